@@ -0,0 +1,65 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// ServerOption is the main context object for the admission webhook.
+type ServerOption struct {
+	Master       string
+	Kubeconfig   string
+	CertFile     string
+	KeyFile      string
+	Port         int
+	DefaultQueue string
+	PrintVersion bool
+}
+
+// NewServerOption creates a new ServerOption with a default config.
+func NewServerOption() *ServerOption {
+	s := ServerOption{}
+	return &s
+}
+
+// AddFlags adds flags for a specific ServerOption to the specified FlagSet
+func (s *ServerOption) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&s.Master, "master", s.Master, "The address of the Kubernetes API server (overrides any value in kubeconfig)")
+	fs.StringVar(&s.Kubeconfig, "kubeconfig", s.Kubeconfig, "Path to kubeconfig file with authorization and master location information")
+	fs.StringVar(&s.CertFile, "tls-cert-file", s.CertFile, "File containing the x509 certificate for HTTPS")
+	fs.StringVar(&s.KeyFile, "tls-private-key-file", s.KeyFile, "File containing the x509 private key matching --tls-cert-file")
+	fs.IntVar(&s.Port, "port", 443, "The port the webhook server listens on")
+	fs.StringVar(&s.DefaultQueue, "default-queue", "default", "The default queue name of the job")
+	fs.BoolVar(&s.PrintVersion, "version", false, "Show version and quit")
+}
+
+func (s *ServerOption) CheckOptionOrDie() error {
+	if s.PrintVersion {
+		return nil
+	}
+	if s.CertFile == "" || s.KeyFile == "" {
+		return fmt.Errorf("tls-cert-file and tls-private-key-file must be specified")
+	}
+	if s.Port <= 0 || s.Port > 65535 {
+		return fmt.Errorf("invalid port %d", s.Port)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/kubernetes-sigs/kube-batch/cmd/kube-batch/app/options"
+	schedcache "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+)
+
+// Dump builds a scheduler cache of the cluster given by opt, and returns
+// the dump of it once it's synced; no scheduler is started.
+func Dump(opt *options.ServerOption) (*schedcache.ClusterDump, error) {
+	config, err := buildConfig(opt.Master, opt.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	cache := schedcache.New(config, opt.SchedulerName, opt.DefaultQueue)
+	go cache.Run(stopCh)
+	if !cache.WaitForCacheSync(stopCh) {
+		return nil, fmt.Errorf("failed to sync scheduler cache")
+	}
+
+	return cache.Dump(), nil
+}
@@ -36,6 +36,8 @@ type ServerOption struct {
 	PrintVersion         bool
 	ListenAddress        string
 	EnablePreemption     bool
+	PluginsDir           string
+	EnableDebugDump      bool
 }
 
 // NewServerOption creates a new CMServer with a default config.
@@ -60,6 +62,15 @@ func (s *ServerOption) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.LockObjectNamespace, "lock-object-namespace", s.LockObjectNamespace, "Define the namespace of the lock object")
 	fs.StringVar(&s.ListenAddress, "listen-address", ":8080", "The address to listen on for HTTP requests.")
 	fs.BoolVar(&s.EnablePreemption, "enable-preemption", false, "Enable preemption")
+	AddPluginsDirFlag(fs, &s.PluginsDir)
+	fs.BoolVar(&s.EnableDebugDump, "enable-debug-dump", false,
+		"Serve the snapshot of cache, including the specs of all pods, at /debug/dump on the listen address without authentication")
+}
+
+// AddPluginsDirFlag adds the flag of the directory of custom plugins, which is
+// shared by kube-batch and its subcommands.
+func AddPluginsDirFlag(fs *pflag.FlagSet, dir *string) {
+	fs.StringVar(dir, "plugins-dir", *dir, "The directory of custom plugins, i.e. Go plugin .so files, to load")
 }
 
 func (s *ServerOption) CheckOptionOrDie() error {
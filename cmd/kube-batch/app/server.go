@@ -26,6 +26,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/kubernetes-sigs/kube-batch/cmd/kube-batch/app/options"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
 	"github.com/kubernetes-sigs/kube-batch/pkg/version"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
@@ -57,11 +58,24 @@ func buildConfig(master, kubeconfig string) (*rest.Config, error) {
 	return rest.InClusterConfig()
 }
 
+// LoadCustomPlugins loads the custom plugins in dir if it's given; it's
+// shared by kube-batch and its subcommands, so they have the same plugins.
+func LoadCustomPlugins(dir string) error {
+	if len(dir) == 0 {
+		return nil
+	}
+	return framework.LoadCustomPlugins(dir)
+}
+
 func Run(opt *options.ServerOption) error {
 	if opt.PrintVersion {
 		version.PrintVersionAndExit(apiVersion)
 	}
 
+	if err := LoadCustomPlugins(opt.PluginsDir); err != nil {
+		return err
+	}
+
 	config, err := buildConfig(opt.Master, opt.Kubeconfig)
 	if err != nil {
 		return err
@@ -80,6 +94,12 @@ func Run(opt *options.ServerOption) error {
 
 	go func() {
 		http.Handle("/metrics", promhttp.Handler())
+		http.Handle(scheduler.ExplainPath, sched.ExplainHandler())
+		// The dump has the specs of all pods, e.g. their env, so it's only
+		// served if enabled explicitly.
+		if opt.EnableDebugDump {
+			http.Handle(scheduler.DumpPath, sched.DumpHandler())
+		}
 		glog.Fatalf("Prometheus Http Server failed %s", http.ListenAndServe(opt.ListenAddress, nil))
 	}()
 
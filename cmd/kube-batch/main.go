@@ -17,17 +17,22 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"time"
 
 	"github.com/golang/glog"
 	"github.com/spf13/pflag"
 
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apiserver/pkg/util/flag"
 
 	"github.com/kubernetes-sigs/kube-batch/cmd/kube-batch/app"
 	"github.com/kubernetes-sigs/kube-batch/cmd/kube-batch/app/options"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler"
+	schedcache "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/simulator"
 
 	// Import default actions/plugins.
 	_ "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions"
@@ -36,7 +41,191 @@ import (
 
 var logFlushFreq = pflag.Duration("log-flush-frequency", 5*time.Second, "Maximum number of seconds between log flushes")
 
+// loadCustomPlugins loads the custom plugins given by --plugins-dir of
+// subcommand like kube-batch, and exits non-zero if it fails.
+func loadCustomPlugins(dir string) {
+	if err := app.LoadCustomPlugins(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// checkConfig validates the scheduler configuration file given by
+// `kube-batch check-config [--plugins-dir <dir>] <file>`, and exits non-zero
+// if it's invalid.
+func checkConfig(args []string) {
+	fs := pflag.NewFlagSet("check-config", pflag.ExitOnError)
+	var pluginsDir string
+	options.AddPluginsDirFlag(fs, &pluginsDir)
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s check-config [--plugins-dir <dir>] <file>\n", os.Args[0])
+		os.Exit(2)
+	}
+	file := fs.Arg(0)
+
+	loadCustomPlugins(pluginsDir)
+
+	if err := scheduler.CheckSchedulerConf(file); err != nil {
+		if agg, ok := err.(utilerrors.Aggregate); ok {
+			for _, e := range agg.Errors() {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", file, e)
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+		}
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s: OK\n", file)
+}
+
+// dumpCluster writes the snapshot of cluster to the file given by
+// `kube-batch dump [--kubeconfig <file>] [--format json|yaml] [-o <file>]`,
+// stdout by default.
+func dumpCluster(args []string) {
+	fs := pflag.NewFlagSet("dump", pflag.ExitOnError)
+	s := options.NewServerOption()
+	fs.StringVar(&s.Master, "master", "", "The address of the Kubernetes API server (overrides any value in kubeconfig)")
+	fs.StringVar(&s.Kubeconfig, "kubeconfig", "", "Path to kubeconfig file with authorization and master location information")
+	fs.StringVar(&s.SchedulerName, "scheduler-name", "kube-batch", "kube-batch will handle pods with the scheduler-name")
+	fs.StringVar(&s.DefaultQueue, "default-queue", "default", "The default queue name of the job")
+	format := fs.String("format", "json", "The format of dump, json or yaml")
+	output := fs.StringP("output", "o", "", "The file to write dump to; stdout if not set")
+	fs.Parse(args)
+
+	dump, err := app.Dump(s)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	data, err := schedcache.EncodeClusterDump(dump, *format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	if len(*output) == 0 {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := ioutil.WriteFile(*output, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+// replay runs the actions of scheduler configuration against the cluster dump
+// given by `kube-batch replay [--scheduler-conf <file>] [--plugins-dir <dir>]
+// [--enable-preemption] <dump>`, and prints the binds, pipelines and
+// evictions.
+func replay(args []string) {
+	fs := pflag.NewFlagSet("replay", pflag.ExitOnError)
+	schedulerConf := fs.String("scheduler-conf", "", "The scheduler configuration file; the default configuration if not set")
+	var pluginsDir string
+	options.AddPluginsDirFlag(fs, &pluginsDir)
+	enablePreemption := fs.Bool("enable-preemption", false, "Enable preemption in replay")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "usage: %s replay [--scheduler-conf <file>] [--plugins-dir <dir>] [--enable-preemption] <dump>\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	loadCustomPlugins(pluginsDir)
+
+	actions, actionOptions, tiers, err := scheduler.LoadSchedulerConf(*schedulerConf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *schedulerConf, err)
+		os.Exit(1)
+	}
+
+	data, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	dump, err := schedcache.DecodeClusterDump(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", fs.Arg(0), err)
+		os.Exit(1)
+	}
+
+	result := scheduler.Replay(dump, actions, actionOptions, tiers, *enablePreemption)
+	for _, d := range result.Binds {
+		fmt.Printf("bind %s/%s %s\n", d.Namespace, d.Name, d.NodeName)
+	}
+	for _, d := range result.Pipelines {
+		fmt.Printf("pipeline %s/%s %s\n", d.Namespace, d.Name, d.NodeName)
+	}
+	for _, d := range result.Evictions {
+		fmt.Printf("evict %s/%s %s: %s\n", d.Namespace, d.Name, d.NodeName, d.Reason)
+	}
+}
+
+// simulate runs the scheduler configuration against the workload trace on the
+// node inventory given by `kube-batch simulate [--scheduler-conf <file>]
+// [--plugins-dir <dir>] [--schedule-period <duration>] [--enable-preemption]
+// <workload> <inventory>`, and prints the report.
+func simulate(args []string) {
+	fs := pflag.NewFlagSet("simulate", pflag.ExitOnError)
+	schedulerConf := fs.String("scheduler-conf", "", "The scheduler configuration file; the default configuration if not set")
+	var pluginsDir string
+	options.AddPluginsDirFlag(fs, &pluginsDir)
+	schedulePeriod := fs.Duration("schedule-period", time.Second, "The period between each scheduling cycle in simulated time")
+	enablePreemption := fs.Bool("enable-preemption", false, "Enable preemption in simulation")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fmt.Fprintf(os.Stderr, "usage: %s simulate [--scheduler-conf <file>] [--plugins-dir <dir>] [--schedule-period <duration>] [--enable-preemption] <workload> <inventory>\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	loadCustomPlugins(pluginsDir)
+
+	actions, actionOptions, tiers, err := scheduler.LoadSchedulerConf(*schedulerConf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *schedulerConf, err)
+		os.Exit(1)
+	}
+
+	workload, err := simulator.LoadWorkload(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	inventory, err := simulator.LoadInventory(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	sim := simulator.New(workload, inventory, actions, actionOptions, tiers)
+	sim.SchedulePeriod = *schedulePeriod
+	sim.EnablePreemption = *enablePreemption
+	sim.Run().Print(os.Stdout)
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "check-config":
+			checkConfig(os.Args[2:])
+			return
+		case "dump":
+			dumpCluster(os.Args[2:])
+			return
+		case "replay":
+			replay(os.Args[2:])
+			return
+		case "simulate":
+			simulate(os.Args[2:])
+			return
+		}
+	}
+
 	s := options.NewServerOption()
 	s.AddFlags(pflag.CommandLine)
 
@@ -0,0 +1,243 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/api/scheduling/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	kbver "github.com/kubernetes-sigs/kube-batch/pkg/client/clientset/versioned"
+)
+
+const (
+	// ValidatePath is the path of the validating webhook.
+	ValidatePath = "/validate"
+	// MutatePath is the path of the mutating webhook.
+	MutatePath = "/mutate"
+)
+
+// PriorityClassGetter gets PriorityClass by name, e.g. the PriorityClasses
+// client of scheduling/v1beta1.
+type PriorityClassGetter interface {
+	Get(name string, options metav1.GetOptions) (*v1beta1.PriorityClass, error)
+}
+
+// Admission validates and defaults PodGroup, Queue and Pod.
+type Admission struct {
+	// DefaultQueue is the queue of PodGroup without spec.queue.
+	DefaultQueue string
+
+	KBClient        kbver.Interface
+	PriorityClasses PriorityClassGetter
+}
+
+// Handler returns the http.Handler serving both webhooks.
+func (a *Admission) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(ValidatePath, func(w http.ResponseWriter, r *http.Request) {
+		serve(w, r, a.validate)
+	})
+	mux.HandleFunc(MutatePath, func(w http.ResponseWriter, r *http.Request) {
+		serve(w, r, a.mutate)
+	})
+	return mux
+}
+
+func serve(w http.ResponseWriter, r *http.Request, admit func(*AdmissionRequest) *AdmissionResponse) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := &AdmissionReview{}
+	if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+		http.Error(w, fmt.Sprintf("failed to decode AdmissionReview: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	resp := admit(review.Request)
+	resp.UID = review.Request.UID
+	review.Response = resp
+	review.Request = nil
+
+	data, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(data); err != nil {
+		glog.Errorf("Failed to write admission response: %v", err)
+	}
+}
+
+func allow() *AdmissionResponse {
+	return &AdmissionResponse{Allowed: true}
+}
+
+func deny(reason metav1.StatusReason, message string) *AdmissionResponse {
+	return &AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  reason,
+			Message: message,
+		},
+	}
+}
+
+func (a *Admission) validate(req *AdmissionRequest) *AdmissionResponse {
+	if req.Operation == Delete {
+		return allow()
+	}
+
+	switch req.Kind.Kind {
+	case "PodGroup":
+		pg := &kbv1.PodGroup{}
+		if err := json.Unmarshal(req.Object.Raw, pg); err != nil {
+			return deny(metav1.StatusReasonBadRequest, err.Error())
+		}
+		var oldPG *kbv1.PodGroup
+		if req.Operation == Update && len(req.OldObject.Raw) != 0 {
+			oldPG = &kbv1.PodGroup{}
+			if err := json.Unmarshal(req.OldObject.Raw, oldPG); err != nil {
+				return deny(metav1.StatusReasonBadRequest, err.Error())
+			}
+		}
+		if errs := a.validatePodGroup(pg, oldPG); len(errs) != 0 {
+			glog.V(3).Infof("Reject PodGroup <%s/%s>: %v", pg.Namespace, pg.Name, errs.ToAggregate())
+			return deny(metav1.StatusReasonInvalid, errs.ToAggregate().Error())
+		}
+	case "Queue":
+		queue := &kbv1.Queue{}
+		if err := json.Unmarshal(req.Object.Raw, queue); err != nil {
+			return deny(metav1.StatusReasonBadRequest, err.Error())
+		}
+		if errs := validateQueue(queue); len(errs) != 0 {
+			glog.V(3).Infof("Reject Queue <%s>: %v", queue.Name, errs.ToAggregate())
+			return deny(metav1.StatusReasonInvalid, errs.ToAggregate().Error())
+		}
+	}
+
+	return allow()
+}
+
+func (a *Admission) mutate(req *AdmissionRequest) *AdmissionResponse {
+	if req.Operation != Create {
+		return allow()
+	}
+
+	var patch []patchOperation
+	switch req.Kind.Kind {
+	case "PodGroup":
+		pg := &kbv1.PodGroup{}
+		if err := json.Unmarshal(req.Object.Raw, pg); err != nil {
+			return deny(metav1.StatusReasonBadRequest, err.Error())
+		}
+		patch = a.mutatePodGroup(pg)
+	case "Pod":
+		pod := &v1.Pod{}
+		if err := json.Unmarshal(req.Object.Raw, pod); err != nil {
+			return deny(metav1.StatusReasonBadRequest, err.Error())
+		}
+		patch = mutatePod(pod)
+	}
+
+	if len(patch) == 0 {
+		return allow()
+	}
+
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return deny(metav1.StatusReasonInternalError, err.Error())
+	}
+
+	pt := PatchTypeJSONPatch
+	resp := allow()
+	resp.Patch = data
+	resp.PatchType = &pt
+	return resp
+}
+
+func (a *Admission) mutatePodGroup(pg *kbv1.PodGroup) []patchOperation {
+	if len(pg.Spec.Queue) != 0 || len(a.DefaultQueue) == 0 {
+		return nil
+	}
+
+	return []patchOperation{{
+		Op:    "add",
+		Path:  "/spec/queue",
+		Value: a.DefaultQueue,
+	}}
+}
+
+func mutatePod(pod *v1.Pod) []patchOperation {
+	if len(pod.Annotations[kbv1.GroupNameAnnotationKey]) != 0 {
+		return nil
+	}
+
+	groupName := podGroupName(pod)
+	if len(groupName) == 0 {
+		return nil
+	}
+
+	if pod.Annotations == nil {
+		return []patchOperation{{
+			Op:    "add",
+			Path:  "/metadata/annotations",
+			Value: map[string]string{kbv1.GroupNameAnnotationKey: groupName},
+		}}
+	}
+
+	return []patchOperation{{
+		Op:    "add",
+		Path:  "/metadata/annotations/" + escapeJSONPointer(kbv1.GroupNameAnnotationKey),
+		Value: groupName,
+	}}
+}
+
+// podGroupName returns the name of PodGroup the pod references, either by
+// the group-name label or by an owner reference to the PodGroup.
+func podGroupName(pod *v1.Pod) string {
+	if gn := pod.Labels[kbv1.GroupNameAnnotationKey]; len(gn) != 0 {
+		return gn
+	}
+
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "PodGroup" && strings.HasPrefix(ref.APIVersion, kbv1.GroupName+"/") {
+			return ref.Name
+		}
+	}
+
+	return ""
+}
+
+func escapeJSONPointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
@@ -0,0 +1,320 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/api/scheduling/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/client/clientset/versioned/fake"
+)
+
+type fakePriorityClasses map[string]*v1beta1.PriorityClass
+
+func (f fakePriorityClasses) Get(name string, options metav1.GetOptions) (*v1beta1.PriorityClass, error) {
+	if pc, found := f[name]; found {
+		return pc, nil
+	}
+	return nil, errors.NewNotFound(v1beta1.Resource("priorityclasses"), name)
+}
+
+func review(t *testing.T, url string, kind string, op Operation, obj, old interface{}) *AdmissionResponse {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("failed to marshal object: %v", err)
+	}
+	var oldRaw []byte
+	if old != nil {
+		if oldRaw, err = json.Marshal(old); err != nil {
+			t.Fatalf("failed to marshal old object: %v", err)
+		}
+	}
+
+	body, err := json.Marshal(&AdmissionReview{
+		Request: &AdmissionRequest{
+			UID:       "uid",
+			Kind:      metav1.GroupVersionKind{Kind: kind},
+			Operation: op,
+			Object:    runtime.RawExtension{Raw: raw},
+			OldObject: runtime.RawExtension{Raw: oldRaw},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal review: %v", err)
+	}
+
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to post review: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	result := &AdmissionReview{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		t.Fatalf("failed to decode review: %v", err)
+	}
+	if result.Response == nil || result.Response.UID != "uid" {
+		t.Fatalf("unexpected response: %v", result.Response)
+	}
+
+	return result.Response
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	// Create the queue through the client: objects given to NewSimpleClientset
+	// are tracked under the scheme's group, not the one the fake client uses.
+	kbClient := fake.NewSimpleClientset()
+	if _, err := kbClient.SchedulingV1alpha1().Queues().Create(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+	}); err != nil {
+		t.Fatalf("failed to create queue: %v", err)
+	}
+
+	a := &Admission{
+		DefaultQueue: "default",
+		KBClient:     kbClient,
+		PriorityClasses: fakePriorityClasses{
+			"high": &v1beta1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "high"}, Value: 100},
+		},
+	}
+	return httptest.NewServer(a.Handler())
+}
+
+func TestValidate(t *testing.T) {
+	server := newServer(t)
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		kind    string
+		op      Operation
+		obj     interface{}
+		old     interface{}
+		allowed bool
+	}{
+		{
+			name: "valid PodGroup",
+			kind: "PodGroup",
+			op:   Create,
+			obj: &kbv1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+				Spec:       kbv1.PodGroupSpec{MinMember: 1, Queue: "default", PriorityClassName: "high"},
+			},
+			allowed: true,
+		},
+		{
+			name: "negative MinMember",
+			kind: "PodGroup",
+			op:   Create,
+			obj: &kbv1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+				Spec:       kbv1.PodGroupSpec{MinMember: -1, Queue: "default"},
+			},
+			allowed: false,
+		},
+		{
+			name: "missing queue",
+			kind: "PodGroup",
+			op:   Create,
+			obj: &kbv1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+				Spec:       kbv1.PodGroupSpec{MinMember: 1, Queue: "q1"},
+			},
+			allowed: false,
+		},
+		{
+			name: "unknown PriorityClassName",
+			kind: "PodGroup",
+			op:   Update,
+			obj: &kbv1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+				Spec:       kbv1.PodGroupSpec{MinMember: 1, PriorityClassName: "low"},
+			},
+			allowed: false,
+		},
+		{
+			name: "status update of PodGroup whose queue is deleted",
+			kind: "PodGroup",
+			op:   Update,
+			obj: &kbv1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+				Spec:       kbv1.PodGroupSpec{MinMember: 1, Queue: "q1", PriorityClassName: "low"},
+				Status:     kbv1.PodGroupStatus{Phase: kbv1.PodGroupRunning},
+			},
+			old: &kbv1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+				Spec:       kbv1.PodGroupSpec{MinMember: 1, Queue: "q1", PriorityClassName: "low"},
+				Status:     kbv1.PodGroupStatus{Phase: kbv1.PodGroupPending},
+			},
+			allowed: true,
+		},
+		{
+			name: "update to missing queue",
+			kind: "PodGroup",
+			op:   Update,
+			obj: &kbv1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+				Spec:       kbv1.PodGroupSpec{MinMember: 1, Queue: "q1"},
+			},
+			old: &kbv1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+				Spec:       kbv1.PodGroupSpec{MinMember: 1, Queue: "default"},
+			},
+			allowed: false,
+		},
+		{
+			name: "guarantee over capability",
+			kind: "Queue",
+			op:   Create,
+			obj: &kbv1.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec: kbv1.QueueSpec{
+					Weight:     1,
+					Capability: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+					Guarantee:  v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+				},
+			},
+			allowed: false,
+		},
+		{
+			name: "unknown queue state",
+			kind: "Queue",
+			op:   Create,
+			obj: &kbv1.Queue{
+				ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+				Spec:       kbv1.QueueSpec{Weight: 1, State: "Unknown"},
+			},
+			allowed: false,
+		},
+	}
+
+	for _, test := range tests {
+		resp := review(t, server.URL+ValidatePath, test.kind, test.op, test.obj, test.old)
+		if resp.Allowed != test.allowed {
+			t.Errorf("case <%s>: expected allowed %v, got %v (%v)",
+				test.name, test.allowed, resp.Allowed, resp.Result)
+		}
+	}
+}
+
+func TestMutate(t *testing.T) {
+	server := newServer(t)
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		kind  string
+		obj   interface{}
+		patch []patchOperation
+	}{
+		{
+			name: "default queue",
+			kind: "PodGroup",
+			obj: &kbv1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+			},
+			patch: []patchOperation{{Op: "add", Path: "/spec/queue", Value: "default"}},
+		},
+		{
+			name: "queue set",
+			kind: "PodGroup",
+			obj: &kbv1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+				Spec:       kbv1.PodGroupSpec{Queue: "q1"},
+			},
+		},
+		{
+			name: "pod owned by PodGroup",
+			kind: "Pod",
+			obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "p1",
+					Namespace: "c1",
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: kbv1.SchemeGroupVersion.String(),
+						Kind:       "PodGroup",
+						Name:       "pg1",
+					}},
+				},
+			},
+			patch: []patchOperation{{
+				Op:    "add",
+				Path:  "/metadata/annotations",
+				Value: map[string]interface{}{kbv1.GroupNameAnnotationKey: "pg1"},
+			}},
+		},
+		{
+			name: "pod labeled with PodGroup",
+			kind: "Pod",
+			obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "p1",
+					Namespace:   "c1",
+					Labels:      map[string]string{kbv1.GroupNameAnnotationKey: "pg1"},
+					Annotations: map[string]string{"a": "b"},
+				},
+			},
+			patch: []patchOperation{{
+				Op:    "add",
+				Path:  "/metadata/annotations/scheduling.k8s.io~1group-name",
+				Value: "pg1",
+			}},
+		},
+		{
+			name: "pod without PodGroup",
+			kind: "Pod",
+			obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "p1", Namespace: "c1"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		resp := review(t, server.URL+MutatePath, test.kind, Create, test.obj, nil)
+		if !resp.Allowed {
+			t.Errorf("case <%s>: expected allowed, got %v", test.name, resp.Result)
+			continue
+		}
+
+		var patch []patchOperation
+		if len(resp.Patch) != 0 {
+			if err := json.Unmarshal(resp.Patch, &patch); err != nil {
+				t.Errorf("case <%s>: failed to decode patch: %v", test.name, err)
+				continue
+			}
+		}
+		if !reflect.DeepEqual(patch, test.patch) {
+			t.Errorf("case <%s>: expected patch %v, got %v", test.name, test.patch, patch)
+		}
+	}
+}
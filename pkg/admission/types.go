@@ -0,0 +1,75 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// The types below mirror the wire format of admission.k8s.io/v1beta1, which
+// is not vendored by kube-batch; only the fields used by the webhook are kept.
+
+// Operation is the type of resource operation being checked for admission control
+type Operation string
+
+const (
+	// Create is the operation of creating an object
+	Create Operation = "CREATE"
+	// Update is the operation of updating an object
+	Update Operation = "UPDATE"
+	// Delete is the operation of deleting an object
+	Delete Operation = "DELETE"
+)
+
+// PatchTypeJSONPatch is the only patch type supported by admission webhooks
+const PatchTypeJSONPatch = "JSONPatch"
+
+// AdmissionReview describes an admission review request/response.
+type AdmissionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *AdmissionRequest  `json:"request,omitempty"`
+	Response        *AdmissionResponse `json:"response,omitempty"`
+}
+
+// AdmissionRequest describes the admission.Attributes for the admission request.
+type AdmissionRequest struct {
+	UID       types.UID               `json:"uid"`
+	Kind      metav1.GroupVersionKind `json:"kind"`
+	Name      string                  `json:"name,omitempty"`
+	Namespace string                  `json:"namespace,omitempty"`
+	Operation Operation               `json:"operation"`
+	Object    runtime.RawExtension    `json:"object,omitempty"`
+	OldObject runtime.RawExtension    `json:"oldObject,omitempty"`
+}
+
+// AdmissionResponse describes an admission response.
+type AdmissionResponse struct {
+	UID       types.UID      `json:"uid"`
+	Allowed   bool           `json:"allowed"`
+	Result    *metav1.Status `json:"status,omitempty"`
+	Patch     []byte         `json:"patch,omitempty"`
+	PatchType *string        `json:"patchType,omitempty"`
+}
+
+// patchOperation is one operation of a JSON patch, RFC 6902.
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+)
+
+// validatePodGroup validates the PodGroup created, or updated from old. The
+// queue and PriorityClass are only checked to exist if they're set or changed,
+// so the status of PodGroup, e.g. updated by scheduler every cycle, is not
+// rejected once they're deleted.
+func (a *Admission) validatePodGroup(pg, old *kbv1.PodGroup) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if pg.Spec.MinMember < 0 {
+		errs = append(errs, field.Invalid(specPath.Child("minMember"), pg.Spec.MinMember,
+			"must be greater than or equal to 0"))
+	}
+
+	for role, num := range pg.Spec.MinTaskMember {
+		if num < 0 {
+			errs = append(errs, field.Invalid(specPath.Child("minTaskMember").Key(role), num,
+				"must be greater than or equal to 0"))
+		}
+	}
+
+	if pg.Spec.MinResources != nil {
+		errs = append(errs, validateResourceList(*pg.Spec.MinResources, specPath.Child("minResources"))...)
+	}
+
+	if pg.Spec.ScheduleTimeoutSeconds != nil && *pg.Spec.ScheduleTimeoutSeconds <= 0 {
+		errs = append(errs, field.Invalid(specPath.Child("scheduleTimeoutSeconds"),
+			*pg.Spec.ScheduleTimeoutSeconds, "must be greater than 0"))
+	}
+
+	queue := pg.Spec.Queue
+	if len(queue) == 0 {
+		queue = a.DefaultQueue
+	}
+	if len(queue) == 0 {
+		errs = append(errs, field.Required(specPath.Child("queue"), "no queue and no default queue"))
+	} else if a.KBClient != nil && (old == nil || old.Spec.Queue != pg.Spec.Queue) {
+		if _, err := a.KBClient.SchedulingV1alpha1().Queues().Get(queue, metav1.GetOptions{}); err != nil {
+			if errors.IsNotFound(err) {
+				errs = append(errs, field.NotFound(specPath.Child("queue"), queue))
+			} else {
+				errs = append(errs, field.InternalError(specPath.Child("queue"), err))
+			}
+		}
+	}
+
+	if len(pg.Spec.PriorityClassName) != 0 && a.PriorityClasses != nil &&
+		(old == nil || old.Spec.PriorityClassName != pg.Spec.PriorityClassName) {
+		if _, err := a.PriorityClasses.Get(pg.Spec.PriorityClassName, metav1.GetOptions{}); err != nil {
+			if errors.IsNotFound(err) {
+				errs = append(errs, field.NotFound(specPath.Child("priorityClassName"), pg.Spec.PriorityClassName))
+			} else {
+				errs = append(errs, field.InternalError(specPath.Child("priorityClassName"), err))
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateQueue(queue *kbv1.Queue) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if queue.Spec.Weight < 0 {
+		errs = append(errs, field.Invalid(specPath.Child("weight"), queue.Spec.Weight,
+			"must be greater than or equal to 0"))
+	}
+
+	errs = append(errs, validateResourceList(queue.Spec.Capability, specPath.Child("capability"))...)
+	errs = append(errs, validateResourceList(queue.Spec.Guarantee, specPath.Child("guarantee"))...)
+
+	for name, guarantee := range queue.Spec.Guarantee {
+		if capability, found := queue.Spec.Capability[name]; found && guarantee.Cmp(capability) > 0 {
+			errs = append(errs, field.Invalid(specPath.Child("guarantee").Key(string(name)), guarantee.String(),
+				"must be less than or equal to capability"))
+		}
+	}
+
+	if len(queue.Spec.Parent) != 0 && queue.Spec.Parent == queue.Name {
+		errs = append(errs, field.Invalid(specPath.Child("parent"), queue.Spec.Parent,
+			"must not be the queue itself"))
+	}
+
+	switch queue.Spec.State {
+	case "", kbv1.QueueStateOpen, kbv1.QueueStateClosed, kbv1.QueueStateDraining:
+	default:
+		errs = append(errs, field.NotSupported(specPath.Child("state"), queue.Spec.State,
+			[]string{string(kbv1.QueueStateOpen), string(kbv1.QueueStateClosed), string(kbv1.QueueStateDraining)}))
+	}
+
+	return errs
+}
+
+func validateResourceList(rl v1.ResourceList, fldPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for name, quantity := range rl {
+		if quantity.Sign() < 0 {
+			errs = append(errs, field.Invalid(fldPath.Key(string(name)), quantity.String(),
+				"must be greater than or equal to 0"))
+		}
+	}
+	return errs
+}
@@ -21,3 +21,12 @@ package v1alpha1
 const GroupNameAnnotationKey = "scheduling.k8s.io/group-name"
 
 const BackfillAnnotationKey = "scheduling.k8s.io/kube-batch/backfill"
+
+// TopologyKeyAnnotationKey is the annotation key of PodGroup to identify
+// the node label key, e.g. rack or zone, that all of its tasks are placed in
+// one domain of; it should match the key given to the topology plugin.
+const TopologyKeyAnnotationKey = "scheduling.k8s.io/topology-key"
+
+// TaskRoleKey is the annotation or label key of Pod to identify its role
+// in the PodGroup, e.g. launcher or worker; the annotation takes precedence.
+const TaskRoleKey = "scheduling.k8s.io/task-role"
@@ -30,12 +30,20 @@ const (
 	// enough resources to it.
 	PodGroupPending PodGroupPhase = "Pending"
 
+	// PodGroupInQueue means the pod group has been admitted by the scheduler, and the
+	// controller can start to create pods for it.
+	PodGroupInQueue PodGroupPhase = "InQueue"
+
 	// PodRunning means `spec.minMember` pods of PodGroups has been in running phase.
 	PodGroupRunning PodGroupPhase = "Running"
 
 	// PodGroupUnknown means part of `spec.minMember` pods are running but the other part can not
 	// be scheduled, e.g. not enough resource; scheduler will wait for related controller to recover it.
 	PodGroupUnknown PodGroupPhase = "Unknown"
+
+	// PodGroupFailed means the pod group failed to be scheduled, e.g. it was not scheduled before
+	// `spec.scheduleTimeoutSeconds`; scheduler will not schedule it anymore.
+	PodGroupFailed PodGroupPhase = "Failed"
 )
 
 type PodGroupConditionType string
@@ -43,6 +51,10 @@ type PodGroupConditionType string
 const (
 	PodGroupUnschedulableType PodGroupConditionType = "Unschedulable"
 	PodGroupBackfilledType    PodGroupConditionType = "Backfilled"
+	PodGroupFailedType        PodGroupConditionType = "Failed"
+	// PodGroupScheduledType is whether `spec.minMember` tasks of the pod group are scheduled;
+	// its LastTransitionTime is when they were scheduled or became unscheduled.
+	PodGroupScheduledType PodGroupConditionType = "Scheduled"
 )
 
 // PodGroupCondition contains details for the current state of this pod group.
@@ -81,6 +93,13 @@ const (
 
 	// NotEnoughPodsReason is probed if there're not enough tasks compared to `spec.minMember`
 	NotEnoughPodsReason string = "NotEnoughTasks"
+
+	// InfeasibleResourcesReason is probed if `spec.minResources` can never be satisfied by
+	// the capability of the queue or the total allocatable resources of the cluster
+	InfeasibleResourcesReason string = "InfeasibleResources"
+
+	// ScheduleTimeoutReason is probed if PodGroup was not scheduled before `spec.scheduleTimeoutSeconds`
+	ScheduleTimeoutReason string = "Timeout"
 )
 
 // +genclient
@@ -124,6 +143,25 @@ type PodGroupSpec struct {
 	// default.
 	// +optional
 	PriorityClassName string `json:"priorityClassName,omitempty" protobuf:"bytes,3,opt,name=priorityClassName"`
+
+	// MinResources defines the minimal resources of members/tasks to run the pod group;
+	// if the minimal resources can never be satisfied by the queue or the cluster, the
+	// pod group will be marked as unschedulable and not be scheduled.
+	// +optional
+	MinResources *v1.ResourceList `json:"minResources,omitempty" protobuf:"bytes,4,opt,name=minResources"`
+
+	// ScheduleTimeoutSeconds defines the deadline, in seconds since the pod group was created or
+	// since its tasks became unscheduled after they were scheduled, e.g. evicted, to schedule
+	// `spec.minMember` tasks of the pod group; if it's not scheduled in time, the pod group will be
+	// marked as failed and not be scheduled anymore.
+	// +optional
+	ScheduleTimeoutSeconds *int32 `json:"scheduleTimeoutSeconds,omitempty" protobuf:"bytes,5,opt,name=scheduleTimeoutSeconds"`
+
+	// MinTaskMember defines the minimal number of members/tasks of each role to run the
+	// pod group, keyed by the role of pod given by "scheduling.k8s.io/task-role"; the
+	// pod group is not ready until the minimal member of every role is met.
+	// +optional
+	MinTaskMember map[string]int32 `json:"minTaskMember,omitempty" protobuf:"bytes,6,rep,name=minTaskMember"`
 }
 
 // PodGroupStatus represents the current state of a pod group.
@@ -162,6 +200,22 @@ type PodGroupList struct {
 	Items []PodGroup `json:"items" protobuf:"bytes,2,rep,name=items"`
 }
 
+// QueueState is the state of a queue.
+type QueueState string
+
+// These are the valid states of queues.
+const (
+	// QueueStateOpen means the queue accepts new work.
+	QueueStateOpen QueueState = "Open"
+
+	// QueueStateClosed means no new task is scheduled in the queue; the running tasks are kept.
+	QueueStateClosed QueueState = "Closed"
+
+	// QueueStateDraining means the running PodGroups of the queue are kept and can still
+	// be scheduled, but no resource is allocated to the pending PodGroups.
+	QueueStateDraining QueueState = "Draining"
+)
+
 // +genclient
 // +genclient:nonNamespaced
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -178,11 +232,62 @@ type Queue struct {
 	// More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#spec-and-status
 	// +optional
 	Spec QueueSpec `json:"spec,omitempty" protobuf:"bytes,2,opt,name=spec"`
+
+	// The status of queue.
+	// +optional
+	Status QueueStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// QueueStatus represents the status of Queue.
+type QueueStatus struct {
+	// The number of 'Unknown' PodGroup in this queue.
+	Unknown int32 `json:"unknown,omitempty" protobuf:"bytes,1,opt,name=unknown"`
+	// The number of 'Pending' PodGroup in this queue.
+	Pending int32 `json:"pending,omitempty" protobuf:"bytes,2,opt,name=pending"`
+	// The number of 'Running' PodGroup in this queue.
+	Running int32 `json:"running,omitempty" protobuf:"bytes,3,opt,name=running"`
+
+	// Deserved is the resources the queue deserves in the last scheduling cycle.
+	// +optional
+	Deserved v1.ResourceList `json:"deserved,omitempty" protobuf:"bytes,4,opt,name=deserved"`
+	// Allocated is the resources allocated to the queue in the last scheduling cycle.
+	// +optional
+	Allocated v1.ResourceList `json:"allocated,omitempty" protobuf:"bytes,5,opt,name=allocated"`
+	// Request is the resources requested by the PodGroups of the queue.
+	// +optional
+	Request v1.ResourceList `json:"request,omitempty" protobuf:"bytes,6,opt,name=request"`
+
+	// State is the current state of the queue.
+	// +optional
+	State QueueState `json:"state,omitempty" protobuf:"bytes,7,opt,name=state"`
+
+	// The number of 'InQueue' PodGroup in this queue.
+	InQueue int32 `json:"inQueue,omitempty" protobuf:"bytes,8,opt,name=inQueue"`
 }
 
 // QueueSpec represents the template of Queue.
 type QueueSpec struct {
 	Weight int32 `json:"weight,omitempty" protobuf:"bytes,1,opt,name=weight"`
+
+	// Capability defines the upper limit of resources the Queue can use; the
+	// resource not listed in Capability is not limited.
+	// +optional
+	Capability v1.ResourceList `json:"capability,omitempty" protobuf:"bytes,2,opt,name=capability"`
+
+	// Guarantee defines the resources reserved for the Queue; they are funded
+	// before the rest of cluster is shared by weight, and will not be reclaimed.
+	// +optional
+	Guarantee v1.ResourceList `json:"guarantee,omitempty" protobuf:"bytes,3,opt,name=guarantee"`
+
+	// Parent is the name of the parent Queue; the Queue shares the resources
+	// deserved by its parent with its siblings. It only takes effect when the
+	// proportion plugin is in hierarchical mode.
+	// +optional
+	Parent string `json:"parent,omitempty" protobuf:"bytes,4,opt,name=parent"`
+
+	// State is the desired state of the queue; defaults to "Open".
+	// +optional
+	State QueueState `json:"state,omitempty" protobuf:"bytes,5,opt,name=state"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
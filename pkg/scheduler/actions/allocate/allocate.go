@@ -17,6 +17,8 @@ limitations under the License.
 package allocate
 
 import (
+	"sort"
+
 	"github.com/golang/glog"
 
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
@@ -24,6 +26,30 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
 )
 
+const (
+	// NodeSamplePercentage is the key for providing the percentage of nodes
+	// to find feasible for each task in YAML; all nodes are checked by default.
+	NodeSamplePercentage = "allocate.nodeSamplePercentage"
+	// Parallelism is the key for providing the number of workers to evaluate
+	// nodes for each task in YAML; nodes are evaluated one by one if any
+	// predicate or node order plugin is not concurrency safe.
+	Parallelism = "allocate.parallelism"
+
+	// minFeasibleNodesToFind is the minimum number of feasible nodes to find
+	// when sampling nodes, the same as kube-scheduler.
+	minFeasibleNodesToFind = 100
+
+	// defaultParallelism is the default number of workers to evaluate nodes,
+	// the same as kube-scheduler.
+	defaultParallelism = 16
+)
+
+// Arguments is the argument schema of allocate action.
+var Arguments = framework.ArgumentSchema{
+	NodeSamplePercentage: framework.ArgumentInt,
+	Parallelism:          framework.ArgumentInt,
+}
+
 type allocateAction struct {
 	ssn *framework.Session
 }
@@ -42,16 +68,40 @@ func (alloc *allocateAction) Execute(ssn *framework.Session) {
 	glog.V(3).Infof("Enter Allocate ...")
 	defer glog.V(3).Infof("Leaving Allocate ...")
 
+	percentage := 100
+	ssn.ActionArguments[alloc.Name()].GetInt(&percentage, NodeSamplePercentage)
+
+	workers := defaultParallelism
+	ssn.ActionArguments[alloc.Name()].GetInt(&workers, Parallelism)
+	if workers < 1 || !ssn.ConcurrencySafe() {
+		glog.V(4).Infof("Evaluate nodes one by one in Session <%v>", ssn.UID)
+		workers = 1
+	}
+
+	// Nodes are evaluated in the order of name, so the result does not
+	// depend on the order of evaluation.
+	allNodes := make([]*api.NodeInfo, 0, len(ssn.Nodes))
+	for _, node := range ssn.Nodes {
+		allNodes = append(allNodes, node)
+	}
+	sort.Slice(allNodes, func(i, j int) bool {
+		return allNodes[i].Name < allNodes[j].Name
+	})
+
 	queues := util.NewPriorityQueue(ssn.QueueOrderFn)
 	jobsMap := map[api.QueueID]*util.PriorityQueue{}
 
 	for _, job := range ssn.Jobs {
-		if queue, found := ssn.Queues[job.Queue]; found {
-			queues.Push(queue)
-		} else {
+		if queue, found := ssn.Queues[job.Queue]; !found {
 			glog.Warningf("Skip adding Job <%s/%s> because its queue %s is not found",
 				job.Namespace, job.Name, job.Queue)
 			continue
+		} else if !queue.CanAllocate(job) {
+			glog.V(4).Infof("Skip adding Job <%s/%s> because its queue %s is %s",
+				job.Namespace, job.Name, job.Queue, queue.State)
+			continue
+		} else {
+			queues.Push(queue)
 		}
 
 		if _, found := jobsMap[job.Queue]; !found {
@@ -107,10 +157,11 @@ func (alloc *allocateAction) Execute(ssn *framework.Session) {
 		glog.V(3).Infof("Try to allocate resource to %d tasks of Job <%v/%v>",
 			tasks.Len(), job.Namespace, job.Name)
 
-		for !tasks.Empty() {
-			predicateNodes := []*api.NodeInfo{}
-			nodeScores := map[int][]*api.NodeInfo{}
+		// Tasks of the job are only bound when the job is ready, so a gang
+		// which partly fits does not hold resources.
+		stmt := ssn.Statement()
 
+		for !tasks.Empty() {
 			task := tasks.Pop().(*api.TaskInfo)
 			assigned := false
 
@@ -125,25 +176,9 @@ func (alloc *allocateAction) Execute(ssn *framework.Session) {
 				job.NodesFitDelta = make(api.NodeResourceMap)
 			}
 
-			for _, node := range ssn.Nodes {
-				// TODO (k82cn): Enable eCache for performance improvement.
-				if err := ssn.PredicateFn(task, node); err != nil {
-					glog.V(3).Infof("Predicates failed for task <%s/%s> on node <%s>: %v",
-						task.Namespace, task.Name, node.Name, err)
-					continue
-				} else {
-					predicateNodes = append(predicateNodes, node)
-				}
-			}
-
-			for _, node := range predicateNodes {
-				score, err := ssn.NodeOrderFn(task, node)
-				if err != nil {
-					glog.V(3).Infof("Error in Calculating Priority for the node:%v", err)
-				} else {
-					nodeScores[score] = append(nodeScores[score], node)
-				}
-			}
+			numNodesToFind := numFeasibleNodesToFind(len(allNodes), percentage)
+			nodes := ssn.PredicateNodes(task, allNodes, numNodesToFind, workers)
+			nodeScores := ssn.PrioritizeNodes(task, nodes, workers)
 
 			selectedNodes := util.SelectBestNode(nodeScores)
 			for _, node := range selectedNodes {
@@ -154,7 +189,7 @@ func (alloc *allocateAction) Execute(ssn *framework.Session) {
 					glog.V(3).Infof("Binding Task <%v/%v> to node <%v>",
 						task.Namespace, task.Name, node.Name)
 
-					if err := ssn.Allocate(task, node.Name, !task.InitResreq.LessEqual(node.Idle)); err != nil {
+					if err := stmt.Allocate(task, node.Name, !task.InitResreq.LessEqual(node.Idle)); err != nil {
 						glog.Errorf("Failed to bind Task %v on %v in Session %v",
 							task.UID, node.Name, ssn.UID)
 						continue
@@ -173,7 +208,7 @@ func (alloc *allocateAction) Execute(ssn *framework.Session) {
 				if task.InitResreq.LessEqual(node.Releasing) {
 					glog.V(3).Infof("Pipelining Task <%v/%v> to node <%v> for <%v> on <%v>",
 						task.Namespace, task.Name, node.Name, task.InitResreq, node.Releasing)
-					if err := ssn.Pipeline(task, node.Name); err != nil {
+					if err := stmt.Pipeline(task, node.Name); err != nil {
 						glog.Errorf("Failed to pipeline Task %v on %v in Session %v",
 							task.UID, node.Name, ssn.UID)
 						continue
@@ -195,9 +230,33 @@ func (alloc *allocateAction) Execute(ssn *framework.Session) {
 			}
 		}
 
+		if ssn.JobReady(job) {
+			stmt.Commit()
+		} else {
+			glog.V(3).Infof("Job <%v/%v> is not ready, discard its allocation",
+				job.Namespace, job.Name)
+			stmt.Discard()
+		}
+
 		// Added Queue back until no job in Queue.
 		queues.Push(queue)
 	}
 }
 
 func (alloc *allocateAction) UnInitialize() {}
+
+// numFeasibleNodesToFind returns the number of feasible nodes to find for a
+// task; it's the given percentage of all nodes, but at least
+// minFeasibleNodesToFind.
+func numFeasibleNodesToFind(numAllNodes, percentage int) int {
+	if percentage <= 0 || percentage >= 100 || numAllNodes <= minFeasibleNodesToFind {
+		return numAllNodes
+	}
+
+	num := numAllNodes * percentage / 100
+	if num < minFeasibleNodesToFind {
+		return minFeasibleNodesToFind
+	}
+
+	return num
+}
@@ -36,6 +36,9 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/drf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/gang"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/nodeorder"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/predicates"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/proportion"
 )
 
@@ -128,18 +131,26 @@ func (ftsu *fakeStatusUpdater) UpdatePodGroup(pg *kbv1.PodGroup) (*kbv1.PodGroup
 	return nil, nil
 }
 
+func (ftsu *fakeStatusUpdater) UpdateQueueStatus(queue *kbv1.Queue) (*kbv1.Queue, error) {
+	// do nothing here
+	return queue, nil
+}
+
 type fakeVolumeBinder struct {
 }
 
 func (fvb *fakeVolumeBinder) AllocateVolumes(task *api.TaskInfo, hostname string) error {
 	return nil
 }
+func (fvb *fakeVolumeBinder) RevertVolumes(task *api.TaskInfo) {
+}
 func (fvb *fakeVolumeBinder) BindVolumes(task *api.TaskInfo) error {
 	return nil
 }
 
 func TestAllocate(t *testing.T) {
 	framework.RegisterPluginBuilder("drf", drf.New)
+	framework.RegisterPluginBuilder("gang", gang.New)
 	framework.RegisterPluginBuilder("proportion", proportion.New)
 	defer framework.CleanupPluginBuilders()
 
@@ -245,6 +256,105 @@ func TestAllocate(t *testing.T) {
 				"c1/p1": "n1",
 			},
 		},
+		{
+			name: "no allocation in Closed queue",
+			podGroups: []*kbv1.PodGroup{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg1",
+						Namespace: "c1",
+					},
+					Spec: kbv1.PodGroupSpec{
+						Queue: "c1",
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg2",
+						Namespace: "c2",
+					},
+					Spec: kbv1.PodGroupSpec{
+						Queue: "c2",
+					},
+				},
+			},
+			pods: []*v1.Pod{
+				buildPod("c1", "p1", "", v1.PodPending, buildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				buildPod("c2", "p1", "", v1.PodPending, buildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+			},
+			nodes: []*v1.Node{
+				buildNode("n1", buildResourceList("2", "4G"), make(map[string]string)),
+			},
+			queues: []*kbv1.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "c1",
+					},
+					Spec: kbv1.QueueSpec{
+						Weight: 1,
+						State:  kbv1.QueueStateClosed,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "c2",
+					},
+					Spec: kbv1.QueueSpec{
+						Weight: 1,
+					},
+				},
+			},
+			expected: map[string]string{
+				"c2/p1": "n1",
+			},
+		},
+		{
+			name: "gang which partly fits is not allocated",
+			podGroups: []*kbv1.PodGroup{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg1",
+						Namespace: "c1",
+					},
+					Spec: kbv1.PodGroupSpec{
+						Queue:     "c1",
+						MinMember: 3,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pg2",
+						Namespace: "c1",
+					},
+					Spec: kbv1.PodGroupSpec{
+						Queue:     "c1",
+						MinMember: 1,
+					},
+				},
+			},
+			pods: []*v1.Pod{
+				buildPod("c1", "p1", "", v1.PodPending, buildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				buildPod("c1", "p2", "", v1.PodPending, buildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				buildPod("c1", "p3", "", v1.PodPending, buildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)),
+				buildPod("c1", "p4", "", v1.PodPending, buildResourceList("1", "1G"), "pg2", make(map[string]string), make(map[string]string)),
+			},
+			nodes: []*v1.Node{
+				buildNode("n1", buildResourceList("2", "4G"), make(map[string]string)),
+			},
+			queues: []*kbv1.Queue{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "c1",
+					},
+					Spec: kbv1.QueueSpec{
+						Weight: 1,
+					},
+				},
+			},
+			expected: map[string]string{
+				"c1/p4": "n1",
+			},
+		},
 	}
 
 	allocate := New()
@@ -285,6 +395,9 @@ func TestAllocate(t *testing.T) {
 					{
 						Name: "drf",
 					},
+					{
+						Name: "gang",
+					},
 					{
 						Name: "proportion",
 					},
@@ -308,3 +421,165 @@ func TestAllocate(t *testing.T) {
 		}
 	}
 }
+
+func TestNumFeasibleNodesToFind(t *testing.T) {
+	tests := []struct {
+		nodes      int
+		percentage int
+		expected   int
+	}{
+		{nodes: 50, percentage: 10, expected: 50},
+		{nodes: 1000, percentage: 100, expected: 1000},
+		{nodes: 1000, percentage: 0, expected: 1000},
+		{nodes: 1000, percentage: 5, expected: 100},
+		{nodes: 5000, percentage: 10, expected: 500},
+	}
+
+	for i, test := range tests {
+		if num := numFeasibleNodesToFind(test.nodes, test.percentage); num != test.expected {
+			t.Errorf("case %d: expected %d nodes of %d at %d%%, got %d",
+				i, test.expected, test.nodes, test.percentage, num)
+		}
+	}
+}
+
+// evenNodePlugin only allows tasks on nodes of even number, and prefers
+// nodes of larger number modulo 3.
+type evenNodePlugin struct{}
+
+func (ep *evenNodePlugin) Name() string {
+	return "evenNode"
+}
+
+func (ep *evenNodePlugin) ConcurrencySafe() bool {
+	return true
+}
+
+func (ep *evenNodePlugin) OnSessionOpen(ssn *framework.Session) {
+	number := func(node *api.NodeInfo) int {
+		var n int
+		fmt.Sscanf(node.Name, "n%d", &n)
+		return n
+	}
+
+	ssn.AddPredicateFn(ep.Name(), func(task *api.TaskInfo, node *api.NodeInfo) error {
+		if number(node)%2 != 0 {
+			return fmt.Errorf("node <%s> is odd", node.Name)
+		}
+		return nil
+	})
+	ssn.AddNodeOrderFn(ep.Name(), func(task *api.TaskInfo, node *api.NodeInfo) (int, error) {
+		return number(node) % 3, nil
+	})
+}
+
+func (ep *evenNodePlugin) OnSessionClose(ssn *framework.Session) {}
+
+// openBenchSession opens a session of the given plugins with numNodes nodes,
+// and a job of one pending task; nodes are in the order of name.
+func openBenchSession(numNodes int, plugins ...string) (*framework.Session, *api.TaskInfo, []*api.NodeInfo) {
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Queues:        make(map[api.QueueID]*api.QueueInfo),
+		Binder:        &fakeBinder{binds: map[string]string{}, c: make(chan string, 1)},
+		StatusUpdater: &fakeStatusUpdater{},
+		VolumeBinder:  &fakeVolumeBinder{},
+
+		Recorder: record.NewFakeRecorder(100),
+	}
+	alloc := buildResourceList("8", "16G")
+	alloc[v1.ResourcePods] = resource.MustParse("110")
+	for i := 0; i < numNodes; i++ {
+		schedulerCache.AddNode(buildNode(fmt.Sprintf("n%05d", i), alloc, make(map[string]string)))
+	}
+	schedulerCache.AddQueue(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1"},
+		Spec:       kbv1.QueueSpec{Weight: 1},
+	})
+	schedulerCache.AddPodGroup(&kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "pg1", Namespace: "c1"},
+		Spec:       kbv1.PodGroupSpec{Queue: "c1"},
+	})
+	schedulerCache.AddPod(buildPod("c1", "p1", "", v1.PodPending, buildResourceList("1", "1G"), "pg1", make(map[string]string), make(map[string]string)))
+
+	options := []conf.PluginOption{}
+	for _, name := range plugins {
+		options = append(options, conf.PluginOption{Name: name})
+	}
+	ssn := framework.OpenSession(schedulerCache, []conf.Tier{{Plugins: options}})
+
+	var task *api.TaskInfo
+	for _, job := range ssn.Jobs {
+		for _, t := range job.Tasks {
+			task = t
+		}
+	}
+
+	nodes := []*api.NodeInfo{}
+	for i := 0; i < numNodes; i++ {
+		nodes = append(nodes, ssn.Nodes[fmt.Sprintf("n%05d", i)])
+	}
+
+	return ssn, task, nodes
+}
+
+func TestPredicateAndPrioritizeNodes(t *testing.T) {
+	framework.RegisterPluginBuilder("evenNode", func(map[string]string) framework.Plugin {
+		return &evenNodePlugin{}
+	})
+	defer framework.CleanupPluginBuilders()
+
+	ssn, task, nodes := openBenchSession(100, "evenNode")
+	defer framework.CloseSession(ssn)
+
+	if !ssn.ConcurrencySafe() {
+		t.Errorf("expected session is concurrency safe")
+	}
+
+	for _, workers := range []int{1, 4, 16} {
+		found := ssn.PredicateNodes(task, nodes, 5, workers)
+		names := []string{}
+		for _, node := range found {
+			names = append(names, node.Name)
+		}
+		expected := []string{"n00000", "n00002", "n00004", "n00006", "n00008"}
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("workers %d: expected feasible nodes %v, got %v", workers, expected, names)
+		}
+
+		scores := map[int][]string{}
+		for score, nodes := range ssn.PrioritizeNodes(task, found, workers) {
+			for _, node := range nodes {
+				scores[score] = append(scores[score], node.Name)
+			}
+		}
+		// Scores 0, 1, 2 are normalized onto 0, 50, 100.
+		expectedScores := map[int][]string{
+			0:   {"n00000", "n00006"},
+			50:  {"n00004"},
+			100: {"n00002", "n00008"},
+		}
+		if !reflect.DeepEqual(scores, expectedScores) {
+			t.Errorf("workers %d: expected node scores %v, got %v", workers, expectedScores, scores)
+		}
+	}
+}
+
+func BenchmarkPredicateAndPrioritizeNodes(b *testing.B) {
+	framework.RegisterPluginBuilder("predicates", predicates.New)
+	framework.RegisterPluginBuilder("nodeorder", nodeorder.New)
+	defer framework.CleanupPluginBuilders()
+
+	ssn, task, nodes := openBenchSession(500, "predicates", "nodeorder")
+	defer framework.CloseSession(ssn)
+
+	for _, workers := range []int{1, defaultParallelism} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				found := ssn.PredicateNodes(task, nodes, len(nodes), workers)
+				ssn.PrioritizeNodes(task, found, workers)
+			}
+		})
+	}
+}
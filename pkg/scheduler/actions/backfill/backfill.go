@@ -23,6 +23,17 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
 )
 
+const (
+	// MaxNodesToScan is the key for providing the maximum number of nodes
+	// to scan for each BestEffort task in YAML; all nodes are scanned by default.
+	MaxNodesToScan = "backfill.maxNodesToScan"
+)
+
+// Arguments is the argument schema of backfill action.
+var Arguments = framework.ArgumentSchema{
+	MaxNodesToScan: framework.ArgumentInt,
+}
+
 type backfillAction struct {
 	ssn *framework.Session
 }
@@ -41,13 +52,30 @@ func (alloc *backfillAction) Execute(ssn *framework.Session) {
 	glog.V(3).Infof("Enter Backfill ...")
 	defer glog.V(3).Infof("Leaving Backfill ...")
 
+	maxNodesToScan := 0
+	ssn.ActionArguments[alloc.Name()].GetInt(&maxNodesToScan, MaxNodesToScan)
+
 	// TODO (k82cn): When backfill, it's also need to balance between Queues.
 	for _, job := range ssn.Jobs {
+		if queue, found := ssn.Queues[job.Queue]; !found || !queue.CanAllocate(job) {
+			glog.V(4).Infof("Skip backfilling Job <%s/%s> because its queue %s is not found or not open",
+				job.Namespace, job.Name, job.Queue)
+			continue
+		}
+
 		for _, task := range job.TaskStatusIndex[api.Pending] {
 			if task.InitResreq.IsEmpty() {
 				// As task did not request resources, so it only need to meet predicates.
 				// TODO (k82cn): need to prioritize nodes to avoid pod hole.
+				scanned := 0
 				for _, node := range ssn.Nodes {
+					if maxNodesToScan > 0 && scanned >= maxNodesToScan {
+						glog.V(3).Infof("Scanned %d nodes for task <%s/%s>, stop backfilling it",
+							scanned, task.Namespace, task.Name)
+						break
+					}
+					scanned++
+
 					// TODO (k82cn): predicates did not consider pod number for now, there'll
 					// be ping-pong case here.
 					if err := ssn.PredicateFn(task, node); err != nil {
@@ -112,12 +112,19 @@ func (ftsu *fakeStatusUpdater) UpdatePodGroup(pg *kbv1.PodGroup) (*kbv1.PodGroup
 	return nil, nil
 }
 
+func (ftsu *fakeStatusUpdater) UpdateQueueStatus(queue *kbv1.Queue) (*kbv1.Queue, error) {
+	// do nothing here
+	return queue, nil
+}
+
 type fakeVolumeBinder struct {
 }
 
 func (fvb *fakeVolumeBinder) AllocateVolumes(task *api.TaskInfo, hostname string) error {
 	return nil
 }
+func (fvb *fakeVolumeBinder) RevertVolumes(task *api.TaskInfo) {
+}
 func (fvb *fakeVolumeBinder) BindVolumes(task *api.TaskInfo) error {
 	return nil
 }
@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package enqueue
+
+import (
+	"github.com/golang/glog"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
+)
+
+// Arguments is the argument schema of enqueue action, which takes no argument.
+var Arguments = framework.ArgumentSchema{}
+
+type enqueueAction struct {
+	ssn *framework.Session
+}
+
+func New() *enqueueAction {
+	return &enqueueAction{}
+}
+
+func (enqueue *enqueueAction) Name() string {
+	return "enqueue"
+}
+
+func (enqueue *enqueueAction) Initialize() {}
+
+func (enqueue *enqueueAction) Execute(ssn *framework.Session) {
+	glog.V(3).Infof("Enter Enqueue ...")
+	defer glog.V(3).Infof("Leaving Enqueue ...")
+
+	queues := util.NewPriorityQueue(ssn.QueueOrderFn)
+	queueMap := map[api.QueueID]*api.QueueInfo{}
+	jobsMap := map[api.QueueID]*util.PriorityQueue{}
+
+	for _, job := range ssn.Jobs {
+		// Only the Pending PodGroups are admitted into InQueue.
+		if job.PodGroup == nil || job.PodGroup.Status.Phase != v1alpha1.PodGroupPending {
+			continue
+		}
+
+		queue, found := ssn.Queues[job.Queue]
+		if !found {
+			glog.Warningf("Skip enqueuing Job <%s/%s> because its queue %s is not found",
+				job.Namespace, job.Name, job.Queue)
+			continue
+		}
+		if !queue.CanAllocate(job) {
+			glog.V(4).Infof("Skip enqueuing Job <%s/%s> because its queue %s is %s",
+				job.Namespace, job.Name, job.Queue, queue.State)
+			continue
+		}
+
+		if _, found := queueMap[queue.UID]; !found {
+			queueMap[queue.UID] = queue
+			queues.Push(queue)
+		}
+
+		if _, found := jobsMap[job.Queue]; !found {
+			jobsMap[job.Queue] = util.NewPriorityQueue(ssn.JobOrderFn)
+		}
+
+		glog.V(4).Infof("Added Job <%s/%s> into Queue <%s>", job.Namespace, job.Name, job.Queue)
+		jobsMap[job.Queue].Push(job)
+	}
+
+	glog.V(3).Infof("Try to enqueue PodGroups of %d Queues", len(jobsMap))
+
+	for {
+		if queues.Empty() {
+			break
+		}
+
+		queue := queues.Pop().(*api.QueueInfo)
+
+		jobs, found := jobsMap[queue.UID]
+		if !found || jobs.Empty() {
+			continue
+		}
+
+		job := jobs.Pop().(*api.JobInfo)
+
+		// Admit PodGroups of a Queue by order; the later ones wait for the
+		// PodGroup which can not be admitted.
+		if !ssn.JobEnqueueable(job) {
+			glog.V(3).Infof("Job <%s/%s> can not be enqueued, skip the rest of Queue <%s>.",
+				job.Namespace, job.Name, queue.Name)
+			continue
+		}
+
+		glog.V(3).Infof("Job <%s/%s> is enqueued.", job.Namespace, job.Name)
+		job.PodGroup.Status.Phase = v1alpha1.PodGroupInQueue
+
+		queues.Push(queue)
+	}
+}
+
+func (enqueue *enqueueAction) UnInitialize() {}
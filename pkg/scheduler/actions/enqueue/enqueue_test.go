@@ -0,0 +1,200 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package enqueue
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache/fake"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/gang"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/proportion"
+)
+
+func buildResourceList(cpu string, memory string) v1.ResourceList {
+	return v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+func buildNode(name string, alloc v1.ResourceList) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: v1.NodeStatus{
+			Capacity:    alloc,
+			Allocatable: alloc,
+		},
+	}
+}
+
+func buildPodGroup(name string, created time.Time, minResources v1.ResourceList) *kbv1.PodGroup {
+	return &kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "c1",
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: kbv1.PodGroupSpec{
+			MinMember:    2,
+			Queue:        "q1",
+			MinResources: &minResources,
+		},
+		Status: kbv1.PodGroupStatus{
+			Phase: kbv1.PodGroupPending,
+		},
+	}
+}
+
+type fakeStatusUpdater struct {
+}
+
+func (ftsu *fakeStatusUpdater) UpdatePodCondition(pod *v1.Pod, podCondition *v1.PodCondition) (*v1.Pod, error) {
+	// do nothing here
+	return pod, nil
+}
+
+func (ftsu *fakeStatusUpdater) UpdatePodGroup(pg *kbv1.PodGroup) (*kbv1.PodGroup, error) {
+	// do nothing here
+	return pg, nil
+}
+
+func (ftsu *fakeStatusUpdater) UpdateQueueStatus(queue *kbv1.Queue) (*kbv1.Queue, error) {
+	// do nothing here
+	return queue, nil
+}
+
+func TestEnqueue(t *testing.T) {
+	framework.RegisterPluginBuilder("gang", gang.New)
+	framework.RegisterPluginBuilder("proportion", proportion.New)
+	defer framework.CleanupPluginBuilders()
+
+	now := time.Now()
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Queues:        make(map[api.QueueID]*api.QueueInfo),
+		StatusUpdater: &fakeStatusUpdater{},
+		Recorder:      record.NewFakeRecorder(100),
+	}
+	schedulerCache.AddNode(buildNode("n1", buildResourceList("4", "8G")))
+	schedulerCache.AddQueue(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "q1",
+		},
+		Spec: kbv1.QueueSpec{
+			Weight: 1,
+		},
+	})
+	// Both PodGroups have no pods, as their controller delays creating pods.
+	schedulerCache.AddPodGroup(buildPodGroup("pg1", now, buildResourceList("3", "1G")))
+	schedulerCache.AddPodGroup(buildPodGroup("pg2", now.Add(time.Second), buildResourceList("3", "1G")))
+
+	ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name: "gang",
+				},
+				{
+					Name: "proportion",
+				},
+			},
+		},
+	})
+	defer framework.CloseSession(ssn)
+
+	New().Execute(ssn)
+
+	expected := map[api.JobID]kbv1.PodGroupPhase{
+		"c1/pg1": kbv1.PodGroupInQueue,
+		"c1/pg2": kbv1.PodGroupPending,
+	}
+	for uid, phase := range expected {
+		job, found := ssn.Jobs[uid]
+		if !found {
+			t.Errorf("expected job <%s> in session", uid)
+			continue
+		}
+		if job.PodGroup.Status.Phase != phase {
+			t.Errorf("expected job <%s> to be %v, got %v", uid, phase, job.PodGroup.Status.Phase)
+		}
+	}
+}
+
+func TestEnqueueKeepInQueue(t *testing.T) {
+	framework.RegisterPluginBuilder("gang", gang.New)
+	framework.RegisterPluginBuilder("proportion", proportion.New)
+	defer framework.CleanupPluginBuilders()
+
+	now := time.Now()
+	fc := fake.New(
+		buildNode("n1", buildResourceList("4", "8G")),
+		&kbv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "q1",
+			},
+			Spec: kbv1.QueueSpec{
+				Weight: 1,
+			},
+		},
+		buildPodGroup("pg1", now, buildResourceList("3", "1G")),
+		buildPodGroup("pg2", now.Add(time.Second), buildResourceList("3", "1G")),
+	)
+
+	// pg1 reserves its MinResources in the first session, and keeps them in
+	// the next session as they still fit.
+	for i := 0; i < 2; i++ {
+		ssn := framework.OpenSession(fc, []conf.Tier{
+			{
+				Plugins: []conf.PluginOption{
+					{
+						Name: "gang",
+					},
+					{
+						Name: "proportion",
+					},
+				},
+			},
+		})
+		New().Execute(ssn)
+		framework.CloseSession(ssn)
+
+		expected := map[string]kbv1.PodGroupPhase{
+			"c1/pg1": kbv1.PodGroupInQueue,
+			"c1/pg2": kbv1.PodGroupPending,
+		}
+		for key, phase := range expected {
+			pg := fc.Get(&kbv1.PodGroup{}, key).(*kbv1.PodGroup)
+			if pg.Status.Phase != phase {
+				t.Errorf("session %d: expected PodGroup <%s> to be %v, got %v", i, key, phase, pg.Status.Phase)
+			}
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions/allocate"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions/backfill"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions/enqueue"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions/preempt"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions/reclaim"
 )
@@ -30,4 +31,12 @@ func init() {
 	framework.RegisterAction(allocate.New())
 	framework.RegisterAction(backfill.New())
 	framework.RegisterAction(preempt.New())
+	framework.RegisterAction(enqueue.New())
+
+	// Argument schemas of actions
+	framework.RegisterActionArguments("reclaim", reclaim.Arguments)
+	framework.RegisterActionArguments("allocate", allocate.Arguments)
+	framework.RegisterActionArguments("backfill", backfill.Arguments)
+	framework.RegisterActionArguments("preempt", preempt.Arguments)
+	framework.RegisterActionArguments("enqueue", enqueue.Arguments)
 }
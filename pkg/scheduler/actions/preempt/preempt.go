@@ -18,6 +18,8 @@ package preempt
 
 import (
 	"fmt"
+	"sort"
+
 	"github.com/golang/glog"
 
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
@@ -26,6 +28,19 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
 )
 
+const (
+	// MaxVictims is the key for providing the maximum number of victims
+	// evicted on one node for each preemptor in YAML; no limit by default.
+	// The victims of lowest priority are evicted first. It does not limit
+	// the victims of reclaim action.
+	MaxVictims = "preempt.maxVictims"
+)
+
+// Arguments is the argument schema of preempt action.
+var Arguments = framework.ArgumentSchema{
+	MaxVictims: framework.ArgumentInt,
+}
+
 type preemptAction struct {
 	ssn *framework.Session
 }
@@ -50,6 +65,9 @@ func (alloc *preemptAction) Execute(ssn *framework.Session) {
     //		return
 	//}
 
+	maxVictims := 0
+	ssn.ActionArguments[alloc.Name()].GetInt(&maxVictims, MaxVictims)
+
 	preemptorsMap := map[api.QueueID]*util.PriorityQueue{}
 
 	preemptorTasks := map[api.JobID]*util.PriorityQueue{}
@@ -66,7 +84,7 @@ func (alloc *preemptAction) Execute(ssn *framework.Session) {
 			queues[queue.UID] = queue
 		}
 
-		if len(job.TaskStatusIndex[api.Pending]) != 0 {
+		if len(job.TaskStatusIndex[api.Pending]) != 0 && queues[job.Queue].CanAllocate(job) {
 
 			if _, found := preemptorsMap[job.Queue]; !found {
 				preemptorsMap[job.Queue] = util.NewPriorityQueue(ssn.JobOrderFn)
@@ -112,7 +130,7 @@ func (alloc *preemptAction) Execute(ssn *framework.Session) {
 				glog.V(3).Infof("Considering preemptor <%s/%s> with status %s",
 					preemptor.Namespace, preemptor.Name, preemptor.Status)
 
-				if preempted, _ := preempt(ssn, stmt, preemptor, ssn.Nodes,
+				if preempted, _ := preempt(ssn, stmt, preemptor, ssn.Nodes, maxVictims,
 					func(task *api.TaskInfo) bool {
 						// Ignore non running task.
 						if task.Status != api.Running {
@@ -162,7 +180,7 @@ func (alloc *preemptAction) Execute(ssn *framework.Session) {
 				preemptor := preemptorTasks[job.UID].Pop().(*api.TaskInfo)
 
 				stmt := ssn.Statement()
-				assigned, _ := preempt(ssn, stmt, preemptor, ssn.Nodes, func(task *api.TaskInfo) bool {
+				assigned, _ := preempt(ssn, stmt, preemptor, ssn.Nodes, maxVictims, func(task *api.TaskInfo) bool {
 					// Ignore non running task.
 					if task.Status != api.Running {
 						return false
@@ -261,10 +279,10 @@ func preempt(
 	stmt *framework.Statement,
 	preemptor *api.TaskInfo,
 	nodes map[string]*api.NodeInfo,
+	maxVictims int,
 	filter func(*api.TaskInfo) bool,
 ) (bool, error) {
 	predicateNodes := []*api.NodeInfo{}
-	nodeScores := map[int][]*api.NodeInfo{}
 	assigned := false
 
 	for _, node := range nodes {
@@ -276,14 +294,7 @@ func preempt(
 			predicateNodes = append(predicateNodes, node)
 		}
 	}
-	for _, node := range predicateNodes {
-		score, err := ssn.NodeOrderFn(preemptor, node)
-		if err != nil {
-			glog.V(3).Infof("Error in Calculating Priority for the node:%v", err)
-		} else {
-			nodeScores[score] = append(nodeScores[score], node)
-		}
-	}
+	nodeScores := ssn.PrioritizeNodes(preemptor, predicateNodes, 1)
 	selectedNodes := util.SelectBestNode(nodeScores)
 	for _, node := range selectedNodes {
 		glog.V(3).Infof("Considering Task <%s/%s> on Node <%s>.",
@@ -302,6 +313,14 @@ func preempt(
 		}
 
 		victims := ssn.Preemptable(preemptor, preemptees)
+		// Preempt the tasks of lowest priority first, and keep them if the
+		// number of victims is limited.
+		sort.Slice(victims, func(i, j int) bool {
+			return ssn.TaskOrderFn(victims[j], victims[i])
+		})
+		if maxVictims > 0 && len(victims) > maxVictims {
+			victims = victims[:maxVictims]
+		}
 		metrics.UpdatePreemptionVictimsCount(len(victims))
 
 		// make sure victims altogether have enough resource for the preemptor
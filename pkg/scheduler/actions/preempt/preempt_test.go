@@ -19,14 +19,101 @@ package preempt
 import (
 	"testing"
 
-	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache/fake"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/drf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/gang"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/priority"
 )
 
+func buildPod(name, nodeName, group string, priority int32) *v1.Pod {
+	phase := v1.PodPending
+	if len(nodeName) != 0 {
+		phase = v1.PodRunning
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:         types.UID("c1-" + name),
+			Name:        name,
+			Namespace:   "c1",
+			Annotations: map[string]string{kbv1.GroupNameAnnotationKey: group},
+		},
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+			Containers: []v1.Container{{
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+				},
+			}},
+			Priority: &priority,
+		},
+		Status: v1.PodStatus{Phase: phase},
+	}
+}
+
+func buildPodGroup(name string) *kbv1.PodGroup {
+	return &kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "c1"},
+		Spec:       kbv1.PodGroupSpec{MinMember: 1, Queue: fake.DefaultQueue},
+	}
+}
+
 func TestPreempt(t *testing.T) {
 	framework.RegisterPluginBuilder("drf", drf.New)
 	defer framework.CleanupPluginBuilders()
 
 	// TODO (k82cn): Add UT cases here.
 }
+
+func TestPreemptMaxVictims(t *testing.T) {
+	framework.RegisterPluginBuilder("gang", gang.New)
+	framework.RegisterPluginBuilder("priority", priority.New)
+	defer framework.CleanupPluginBuilders()
+
+	alloc := v1.ResourceList{
+		v1.ResourceCPU:  resource.MustParse("3"),
+		v1.ResourcePods: resource.MustParse("10"),
+	}
+
+	// The victims are given by map, run it several times to cover their order.
+	for i := 0; i < 10; i++ {
+		fc := fake.New(
+			&kbv1.Queue{ObjectMeta: metav1.ObjectMeta{Name: fake.DefaultQueue}},
+			&v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+				Status:     v1.NodeStatus{Capacity: alloc, Allocatable: alloc},
+			},
+			buildPodGroup("pg1"),
+			buildPod("high", "n1", "pg1", 3),
+			buildPod("low", "n1", "pg1", 1),
+			buildPod("middle", "n1", "pg1", 2),
+			buildPodGroup("pg2"),
+			buildPod("preemptor", "", "pg2", 4),
+		)
+
+		ssn := framework.OpenSession(fc, []conf.Tier{{
+			Plugins: []conf.PluginOption{{Name: "gang"}, {Name: "priority"}},
+		}})
+
+		preemptor := ssn.Jobs["c1/pg2"].TaskStatusIndex[api.Pending]["c1-preemptor"]
+		stmt := ssn.Statement()
+		assigned, err := preempt(ssn, stmt, preemptor, ssn.Nodes, 1, nil)
+		if err != nil || !assigned {
+			t.Fatalf("expected preemptor is assigned, got %v, %v", assigned, err)
+		}
+		stmt.Commit()
+		framework.CloseSession(ssn)
+
+		if len(fc.Evictions) != 1 || len(fc.Evictions["c1/low"]) == 0 {
+			t.Fatalf("expected only the task of lowest priority is evicted, got %v", fc.Evictions)
+		}
+	}
+}
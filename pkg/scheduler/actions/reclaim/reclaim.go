@@ -24,6 +24,9 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/util"
 )
 
+// Arguments is the argument schema of reclaim action, which takes no argument.
+var Arguments = framework.ArgumentSchema{}
+
 type reclaimAction struct {
 	ssn *framework.Session
 }
@@ -67,7 +70,7 @@ func (alloc *reclaimAction) Execute(ssn *framework.Session) {
 			}
 		}
 
-		if len(job.TaskStatusIndex[api.Pending]) != 0 {
+		if len(job.TaskStatusIndex[api.Pending]) != 0 && queueMap[job.Queue].CanAllocate(job) {
 			if _, found := preemptorsMap[job.Queue]; !found {
 				preemptorsMap[job.Queue] = util.NewPriorityQueue(ssn.JobOrderFn)
 			}
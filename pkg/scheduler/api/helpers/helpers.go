@@ -32,6 +32,16 @@ func Min(l, r *api.Resource) *api.Resource {
 	return res
 }
 
+func Max(l, r *api.Resource) *api.Resource {
+	res := &api.Resource{}
+
+	res.MilliCPU = math.Max(l.MilliCPU, r.MilliCPU)
+	res.MilliGPU = math.Max(l.MilliGPU, r.MilliGPU)
+	res.Memory = math.Max(l.Memory, r.Memory)
+
+	return res
+}
+
 func Share(l, r float64) float64 {
 	var share float64
 	if r == 0 {
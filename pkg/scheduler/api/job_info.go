@@ -50,6 +50,9 @@ type TaskInfo struct {
 	Priority    int32
 	VolumeReady bool
 
+	// Role is the role of the task in its job, e.g. launcher or worker.
+	Role string
+
 	Pod *v1.Pod
 
 	// Indicates whether or not the task is a backfill task. Either persist
@@ -69,6 +72,14 @@ func getJobID(pod *v1.Pod) JobID {
 	return ""
 }
 
+func getTaskRole(pod *v1.Pod) string {
+	if role, found := pod.Annotations[v1alpha1.TaskRoleKey]; found && len(role) != 0 {
+		return role
+	}
+
+	return pod.Labels[v1alpha1.TaskRoleKey]
+}
+
 func IsBackfill(pod *v1.Pod) bool {
 	if len(pod.Annotations) != 0 {
 		if val, found := pod.Annotations[v1alpha1.BackfillAnnotationKey]; found && len(val) != 0 {
@@ -95,6 +106,7 @@ func NewTaskInfo(pod *v1.Pod) *TaskInfo {
 		NodeName:   pod.Spec.NodeName,
 		Status:     getTaskStatus(pod),
 		Priority:   1,
+		Role:       getTaskRole(pod),
 		Pod:        pod,
 		Resreq:     req,
 		InitResreq: initResreq,
@@ -117,6 +129,7 @@ func (ti *TaskInfo) Clone() *TaskInfo {
 		NodeName:    ti.NodeName,
 		Status:      ti.Status,
 		Priority:    ti.Priority,
+		Role:        ti.Role,
 		Pod:         ti.Pod,
 		Resreq:      ti.Resreq.Clone(),
 		InitResreq:  ti.InitResreq.Clone(),
@@ -149,6 +162,10 @@ type JobInfo struct {
 
 	NodeSelector map[string]string
 	MinAvailable int32
+	// MinResources is the minimal resources to run the job, nil if not specified.
+	MinResources *Resource
+	// MinTaskMember is the minimal number of tasks of each role to run the job.
+	MinTaskMember map[string]int32
 
 	NodesFitDelta NodeResourceMap
 
@@ -195,6 +212,11 @@ func (ji *JobInfo) SetPodGroup(pg *v1alpha1.PodGroup) {
 	ji.Name = pg.Name
 	ji.Namespace = pg.Namespace
 	ji.MinAvailable = pg.Spec.MinMember
+	ji.MinResources = nil
+	if pg.Spec.MinResources != nil {
+		ji.MinResources = NewResource(*pg.Spec.MinResources)
+	}
+	ji.MinTaskMember = pg.Spec.MinTaskMember
 	ji.Queue = QueueID(pg.Spec.Queue)
 	ji.CreationTimestamp = pg.GetCreationTimestamp()
 
@@ -314,6 +336,17 @@ func (ji *JobInfo) Clone() *JobInfo {
 
 	ji.CreationTimestamp.DeepCopyInto(&info.CreationTimestamp)
 
+	if ji.MinResources != nil {
+		info.MinResources = ji.MinResources.Clone()
+	}
+
+	if ji.MinTaskMember != nil {
+		info.MinTaskMember = map[string]int32{}
+		for role, min := range ji.MinTaskMember {
+			info.MinTaskMember[role] = min
+		}
+	}
+
 	for k, v := range ji.NodeSelector {
 		info.NodeSelector[k] = v
 	}
@@ -374,15 +407,41 @@ func (ji *JobInfo) FitError() string {
 func (ji *JobInfo) GetReadiness() JobReadiness {
 	allocatedTasks := ji.GetTasks(AllocatedStatuses()...)
 	allocatedTasksCnt := int32(len(allocatedTasks))
-	if allocatedTasksCnt >= ji.MinAvailable {
+	if allocatedTasksCnt >= ji.MinAvailable &&
+		ji.MinTaskMemberMet(ji.TaskNumOfRoles(AllocatedStatuses()...)) {
 		return Ready
 	}
 
 	allocatedOverBackfillTasks := ji.GetTasks(AllocatedOverBackfill)
 	allocatedOverBackfillTasksCnt := int32(len(allocatedOverBackfillTasks))
-	if allocatedTasksCnt + allocatedOverBackfillTasksCnt >= ji.MinAvailable {
+	if allocatedTasksCnt + allocatedOverBackfillTasksCnt >= ji.MinAvailable &&
+		ji.MinTaskMemberMet(ji.TaskNumOfRoles(append(AllocatedStatuses(), AllocatedOverBackfill)...)) {
 		return AlmostReady
 	}
 
 	return NotReady
+}
+
+// TaskNumOfRoles returns the number of tasks of each role in the given statuses.
+func (ji *JobInfo) TaskNumOfRoles(statuses ...TaskStatus) map[string]int32 {
+	nums := map[string]int32{}
+	for _, status := range statuses {
+		for _, task := range ji.TaskStatusIndex[status] {
+			nums[task.Role]++
+		}
+	}
+
+	return nums
+}
+
+// MinTaskMemberMet returns whether the minimal member of every role is met by
+// the given number of tasks of each role.
+func (ji *JobInfo) MinTaskMemberMet(nums map[string]int32) bool {
+	for role, min := range ji.MinTaskMember {
+		if nums[role] < min {
+			return false
+		}
+	}
+
+	return true
 }
\ No newline at end of file
@@ -234,3 +234,45 @@ func TestIsBackfill(t *testing.T) {
 	}
 }
 
+
+func TestGetReadinessWithRoles(t *testing.T) {
+	owner := buildOwnerReference("uid")
+	launcher := buildPod("c1", "launcher", "n1", v1.PodRunning, buildResourceList("1000m", "1G"), []metav1.OwnerReference{owner},
+		map[string]string{v1alpha1.TaskRoleKey: "launcher"})
+	worker1 := buildPod("c1", "worker1", "n1", v1.PodRunning, buildResourceList("1000m", "1G"), []metav1.OwnerReference{owner},
+		map[string]string{v1alpha1.TaskRoleKey: "worker"})
+	worker2 := buildPod("c1", "worker2", "n1", v1.PodRunning, buildResourceList("1000m", "1G"), []metav1.OwnerReference{owner},
+		map[string]string{v1alpha1.TaskRoleKey: "worker"})
+
+	minTaskMember := map[string]int32{"launcher": 1, "worker": 2}
+
+	tests := []struct {
+		name     string
+		pods     []*v1.Pod
+		expected JobReadiness
+	}{
+		{
+			name:     "all roles are ready",
+			pods:     []*v1.Pod{launcher, worker1, worker2},
+			expected: Ready,
+		},
+		{
+			name:     "no launcher",
+			pods:     []*v1.Pod{worker1, worker2},
+			expected: NotReady,
+		},
+	}
+
+	for i, test := range tests {
+		job := NewJobInfo("job")
+		job.MinAvailable = 2
+		job.MinTaskMember = minTaskMember
+		for _, pod := range test.pods {
+			job.AddTaskInfo(NewTaskInfo(pod))
+		}
+
+		if actual := job.GetReadiness(); actual != test.expected {
+			t.Errorf("case %d (%s): expected: %v, got %v ", i, test.name, test.expected, actual)
+		}
+	}
+}
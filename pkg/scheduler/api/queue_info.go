@@ -30,16 +30,29 @@ type QueueInfo struct {
 
 	Weight int32
 
+	State arbcorev1.QueueState
+
 	Queue *arbcorev1.Queue
 }
 
 func NewQueueInfo(queue *arbcorev1.Queue) *QueueInfo {
+	state := queue.Spec.State
+	if len(state) == 0 {
+		state = arbcorev1.QueueStateOpen
+	}
+	// The Queue which is being deleted does not accept new work.
+	if queue.DeletionTimestamp != nil {
+		state = arbcorev1.QueueStateClosed
+	}
+
 	return &QueueInfo{
 		UID:  QueueID(queue.Name),
 		Name: queue.Name,
 
 		Weight: queue.Spec.Weight,
 
+		State: state,
+
 		Queue: queue,
 	}
 }
@@ -49,6 +62,22 @@ func (q *QueueInfo) Clone() *QueueInfo {
 		UID:    q.UID,
 		Name:   q.Name,
 		Weight: q.Weight,
-		Queue:  q.Queue,
+		State:  q.State,
+		// Deep copy Queue, so its status can be updated within a session.
+		Queue: q.Queue.DeepCopy(),
 	}
 }
+
+// CanAllocate returns whether the pending tasks of the job can be allocated in the
+// queue: nothing is allocated in Closed queue, and only the tasks of running PodGroups
+// are allocated in Draining queue.
+func (q *QueueInfo) CanAllocate(job *JobInfo) bool {
+	switch q.State {
+	case arbcorev1.QueueStateClosed:
+		return false
+	case arbcorev1.QueueStateDraining:
+		return job.PodGroup != nil && job.PodGroup.Status.Phase == arbcorev1.PodGroupRunning
+	}
+
+	return true
+}
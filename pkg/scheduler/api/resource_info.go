@@ -21,6 +21,7 @@ import (
 	"math"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 type Resource struct {
@@ -72,6 +73,20 @@ func NewResource(rl v1.ResourceList) *Resource {
 	return r
 }
 
+// ResourceList converts the Resource back to v1.ResourceList.
+func (r *Resource) ResourceList() v1.ResourceList {
+	rl := v1.ResourceList{
+		v1.ResourceCPU:    *resource.NewMilliQuantity(int64(r.MilliCPU), resource.DecimalSI),
+		v1.ResourceMemory: *resource.NewQuantity(int64(r.Memory), resource.BinarySI),
+	}
+
+	if r.MilliGPU > 0 {
+		rl[GPUResourceName] = *resource.NewMilliQuantity(int64(r.MilliGPU), resource.DecimalSI)
+	}
+
+	return rl
+}
+
 func (r *Resource) IsEmpty() bool {
 	return r.MilliCPU < minMilliCPU && r.Memory < minMemory && r.MilliGPU < minMilliGPU
 }
@@ -144,4 +144,8 @@ type EvictableFn func(*TaskInfo, []*TaskInfo) []*TaskInfo
 // NodeOrderFn is the func declaration used to get priority score for a node for a particular task.
 type NodeOrderFn func(*TaskInfo, *NodeInfo) (int, error)
 
+// BatchNodeOrderFn is the func declaration used to get priority scores of all nodes for a particular task,
+// by node name.
+type BatchNodeOrderFn func(*TaskInfo, []*NodeInfo) (map[string]int, error)
+
 type BackFillEligibleFn func(interface{}) bool
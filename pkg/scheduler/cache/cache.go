@@ -18,6 +18,7 @@ package cache
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -26,6 +27,7 @@ import (
 
 	"k8s.io/api/core/v1"
 	"k8s.io/api/scheduling/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -62,6 +64,13 @@ func init() {
 	utilruntime.Must(schemeBuilder.AddToScheme(kbschema.Scheme))
 }
 
+const (
+	// PodNamespaceEnv is the environment variable of kube-batch's Pod namespace.
+	PodNamespaceEnv = "POD_NAMESPACE"
+	// PodNameEnv is the environment variable of kube-batch's Pod name.
+	PodNameEnv = "POD_NAME"
+)
+
 // New returns a Cache implementation.
 func New(config *rest.Config, schedulerName string, defaultQueue string) Cache {
 	return newSchedulerCache(config, schedulerName, defaultQueue)
@@ -161,6 +170,11 @@ func (su *defaultStatusUpdater) UpdatePodGroup(pg *v1alpha1.PodGroup) (*v1alpha1
 	return su.kbclient.SchedulingV1alpha1().PodGroups(pg.Namespace).Update(pg)
 }
 
+// Update the status of queue
+func (su *defaultStatusUpdater) UpdateQueueStatus(queue *v1alpha1.Queue) (*v1alpha1.Queue, error) {
+	return su.kbclient.SchedulingV1alpha1().Queues().UpdateStatus(queue)
+}
+
 type defaultVolumeBinder struct {
 	volumeBinder *volumebinder.VolumeBinder
 }
@@ -173,6 +187,18 @@ func (dvb *defaultVolumeBinder) AllocateVolumes(task *api.TaskInfo, hostname str
 	return err
 }
 
+// RevertVolumes reverts what AllocateVolumes did to the task: the node of pod
+// and its volume bindings. No PV or PVC is assumed by AllocateVolumes, as the
+// bindings of pod are never found by FindPodVolumes in kube-batch.
+func (dvb *defaultVolumeBinder) RevertVolumes(task *api.TaskInfo) {
+	if task.VolumeReady {
+		return
+	}
+
+	task.Pod.Spec.NodeName = ""
+	dvb.volumeBinder.Binder.GetBindingsCache().DeleteBindings(task.Pod)
+}
+
 // BindVolume binds volumes to the task
 func (dvb *defaultVolumeBinder) BindVolumes(task *api.TaskInfo) error {
 	// If task's volumes are ready, did not bind them again.
@@ -436,6 +462,11 @@ func (sc *SchedulerCache) AllocateVolumes(task *api.TaskInfo, hostname string) e
 	return sc.VolumeBinder.AllocateVolumes(task, hostname)
 }
 
+// RevertVolumes reverts the volumes allocated to the task
+func (sc *SchedulerCache) RevertVolumes(task *api.TaskInfo) {
+	sc.VolumeBinder.RevertVolumes(task)
+}
+
 // BindVolume binds volumes to the task
 func (sc *SchedulerCache) BindVolumes(task *api.TaskInfo) error {
 	return sc.VolumeBinder.BindVolumes(task)
@@ -485,6 +516,8 @@ func (sc *SchedulerCache) processCleanupJob() {
 	if kbapi.JobTerminated(job) {
 		delete(sc.Jobs, job.UID)
 		glog.V(3).Infof("Job <%v:%v/%v> was deleted.", job.UID, job.Namespace, job.Name)
+
+		sc.cleanupQueue(job.Queue)
 	} else {
 		// Retry
 		sc.deleteJob(job)
@@ -642,6 +675,23 @@ func (sc *SchedulerCache) RecordJobStatusEvent(job *kbapi.JobInfo) {
 	}
 }
 
+// RecordEvent records an event of kube-batch itself against its own Pod,
+// which is given by POD_NAMESPACE and POD_NAME through downward API.
+func (sc *SchedulerCache) RecordEvent(eventType, reason, message string) {
+	namespace, name := os.Getenv(PodNamespaceEnv), os.Getenv(PodNameEnv)
+	if len(namespace) == 0 || len(name) == 0 {
+		glog.V(3).Infof("Skip event <%s>, %s or %s is not set: %s", reason, PodNamespaceEnv, PodNameEnv, message)
+		return
+	}
+
+	ref := &v1.ObjectReference{
+		Kind:      "Pod",
+		Namespace: namespace,
+		Name:      name,
+	}
+	sc.Recorder.Event(ref, eventType, reason, message)
+}
+
 // UpdateJobStatus update the status of job and its tasks.
 func (sc *SchedulerCache) UpdateJobStatus(job *kbapi.JobInfo) (*kbapi.JobInfo, error) {
 	if !shadowPodGroup(job.PodGroup) {
@@ -656,3 +706,41 @@ func (sc *SchedulerCache) UpdateJobStatus(job *kbapi.JobInfo) (*kbapi.JobInfo, e
 
 	return job, nil
 }
+
+// UpdateQueueStatus update the status of queue if it's changed.
+func (sc *SchedulerCache) UpdateQueueStatus(queue *kbapi.QueueInfo) error {
+	sc.Mutex.Lock()
+	qi, found := sc.Queues[queue.UID]
+	if !found {
+		sc.Mutex.Unlock()
+		return fmt.Errorf("failed to find queue <%s>", queue.Name)
+	}
+
+	// The Queue is being deleted, or its status is not changed; no need to
+	// update its status.
+	if qi.Queue.DeletionTimestamp != nil || equality.Semantic.DeepEqual(qi.Queue.Status, queue.Queue.Status) {
+		sc.Mutex.Unlock()
+		return nil
+	}
+
+	q := qi.Queue.DeepCopy()
+	q.Status = queue.Queue.Status
+	sc.Mutex.Unlock()
+
+	// The status is updated without lock, so the informers are not blocked
+	// by the request to API server.
+	newQueue, err := sc.StatusUpdater.UpdateQueueStatus(q)
+	if err != nil {
+		return err
+	}
+
+	sc.Mutex.Lock()
+	defer sc.Mutex.Unlock()
+
+	// The Queue may be updated or deleted by informer in the meantime.
+	if qi, found := sc.Queues[queue.UID]; found && qi.Queue.ResourceVersion == q.ResourceVersion {
+		qi.Queue = newQueue
+	}
+
+	return nil
+}
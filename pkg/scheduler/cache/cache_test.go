@@ -26,6 +26,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
 )
 
@@ -258,3 +259,39 @@ func TestAddNode(t *testing.T) {
 		}
 	}
 }
+
+func TestDeleteQueue(t *testing.T) {
+	queue := &kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "q1",
+		},
+	}
+
+	job := api.NewJobInfo("j1")
+	job.Queue = "q1"
+
+	cache := &SchedulerCache{
+		Jobs: map[api.JobID]*api.JobInfo{
+			job.UID: job,
+		},
+		Queues: make(map[api.QueueID]*api.QueueInfo),
+	}
+
+	cache.AddQueue(queue)
+	cache.DeleteQueue(queue)
+
+	qi, found := cache.Queues["q1"]
+	if !found {
+		t.Fatalf("expected deletion of queue <q1> to be deferred")
+	}
+	if qi.State != kbv1.QueueStateClosed {
+		t.Errorf("expected queue <q1> to be %v, got %v", kbv1.QueueStateClosed, qi.State)
+	}
+
+	delete(cache.Jobs, job.UID)
+	cache.cleanupQueue(job.Queue)
+
+	if _, found := cache.Queues["q1"]; found {
+		t.Errorf("expected queue <q1> to be deleted")
+	}
+}
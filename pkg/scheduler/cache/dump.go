@@ -0,0 +1,217 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/api/scheduling/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	kbapi "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+)
+
+const (
+	// DumpAPIVersion is the version of the format of cluster dump.
+	DumpAPIVersion = "kube-batch/v1alpha1"
+	// DumpKind is the kind of cluster dump.
+	DumpKind = "ClusterDump"
+)
+
+// ClusterDump is the serializable form of a cluster snapshot: jobs are kept
+// as their PodGroups and tasks as their Pods, so the dump can be loaded into
+// a SchedulerCache the same way as informers do. PodDisruptionBudgets and
+// volumes are not included.
+type ClusterDump struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	// Time is when the snapshot was taken.
+	Time metav1.Time `json:"time"`
+	// DefaultQueue is the queue of the Pods without PodGroup.
+	DefaultQueue string `json:"defaultQueue,omitempty"`
+
+	Nodes           []*v1.Node               `json:"nodes,omitempty"`
+	Pods            []*v1.Pod                `json:"pods,omitempty"`
+	PodGroups       []*v1alpha1.PodGroup     `json:"podGroups,omitempty"`
+	Queues          []*v1alpha1.Queue        `json:"queues,omitempty"`
+	PriorityClasses []*v1beta1.PriorityClass `json:"priorityClasses,omitempty"`
+}
+
+// NewClusterDump builds the dump of cluster snapshot. The Pods of tasks are
+// updated to the status in snapshot, e.g. the node of binding task, so they
+// are restored to the same status.
+func NewClusterDump(snapshot *kbapi.ClusterInfo, priorityClasses []*v1beta1.PriorityClass, defaultQueue string) *ClusterDump {
+	dump := &ClusterDump{
+		APIVersion:      DumpAPIVersion,
+		Kind:            DumpKind,
+		Time:            metav1.Now(),
+		DefaultQueue:    defaultQueue,
+		PriorityClasses: priorityClasses,
+	}
+
+	pods := map[kbapi.TaskID]*v1.Pod{}
+	addTask := func(task *kbapi.TaskInfo) {
+		if task.Pod == nil {
+			return
+		}
+		if _, found := pods[task.UID]; found {
+			return
+		}
+
+		pod := task.Pod.DeepCopy()
+		if len(pod.Spec.NodeName) == 0 {
+			pod.Spec.NodeName = task.NodeName
+		}
+		if task.Status == kbapi.Releasing && pod.DeletionTimestamp == nil {
+			pod.DeletionTimestamp = &dump.Time
+		}
+		pods[task.UID] = pod
+	}
+
+	for _, node := range snapshot.Nodes {
+		if node.Node != nil {
+			dump.Nodes = append(dump.Nodes, node.Node)
+		}
+		for _, task := range node.Tasks {
+			addTask(task)
+		}
+	}
+
+	for _, job := range snapshot.Jobs {
+		if !shadowPodGroup(job.PodGroup) {
+			dump.PodGroups = append(dump.PodGroups, job.PodGroup)
+		}
+		for _, task := range job.Tasks {
+			addTask(task)
+		}
+	}
+
+	for _, pod := range pods {
+		dump.Pods = append(dump.Pods, pod)
+	}
+
+	for _, queue := range snapshot.Queues {
+		if queue.Queue != nil {
+			dump.Queues = append(dump.Queues, queue.Queue)
+		}
+	}
+
+	sortObjects(dump.Nodes, func(i int) metav1.Object { return dump.Nodes[i] })
+	sortObjects(dump.Pods, func(i int) metav1.Object { return dump.Pods[i] })
+	sortObjects(dump.PodGroups, func(i int) metav1.Object { return dump.PodGroups[i] })
+	sortObjects(dump.Queues, func(i int) metav1.Object { return dump.Queues[i] })
+	sortObjects(dump.PriorityClasses, func(i int) metav1.Object { return dump.PriorityClasses[i] })
+
+	return dump
+}
+
+// sortObjects sorts the slice of objects by namespace and name, so the dump
+// of the same snapshot is the same.
+func sortObjects(slice interface{}, object func(i int) metav1.Object) {
+	sort.SliceStable(slice, func(i, j int) bool {
+		l, r := object(i), object(j)
+		if l.GetNamespace() != r.GetNamespace() {
+			return l.GetNamespace() < r.GetNamespace()
+		}
+		return l.GetName() < r.GetName()
+	})
+}
+
+// Dump returns the dump of the snapshot of cache.
+func (sc *SchedulerCache) Dump() *ClusterDump {
+	snapshot := sc.Snapshot()
+
+	sc.Mutex.Lock()
+	priorityClasses := make([]*v1beta1.PriorityClass, 0, len(sc.PriorityClasses))
+	for _, pc := range sc.PriorityClasses {
+		priorityClasses = append(priorityClasses, pc)
+	}
+	defaultQueue := sc.defaultQueue
+	sc.Mutex.Unlock()
+
+	return NewClusterDump(snapshot, priorityClasses, defaultQueue)
+}
+
+// EncodeClusterDump encodes the dump in the format, "json" or "yaml".
+func EncodeClusterDump(dump *ClusterDump, format string) ([]byte, error) {
+	switch format {
+	case "json":
+		return json.MarshalIndent(dump, "", "  ")
+	case "yaml":
+		return yaml.Marshal(dump)
+	}
+
+	return nil, fmt.Errorf("unknown format <%s> of cluster dump, json or yaml is supported", format)
+}
+
+// DecodeClusterDump decodes the dump from JSON or YAML, and checks its version.
+func DecodeClusterDump(data []byte) (*ClusterDump, error) {
+	dump := &ClusterDump{}
+	if err := yaml.Unmarshal(data, dump); err != nil {
+		return nil, err
+	}
+
+	if dump.APIVersion != DumpAPIVersion || dump.Kind != DumpKind {
+		return nil, fmt.Errorf("unsupported cluster dump <%s, %s>, expected <%s, %s>",
+			dump.APIVersion, dump.Kind, DumpAPIVersion, DumpKind)
+	}
+
+	return dump, nil
+}
+
+// NewFromDump returns a SchedulerCache with the objects of dump, which is not
+// connected to API server; its Binder, Evictor, StatusUpdater and VolumeBinder
+// are set by caller.
+func NewFromDump(dump *ClusterDump) *SchedulerCache {
+	sc := &SchedulerCache{
+		Jobs:            make(map[kbapi.JobID]*kbapi.JobInfo),
+		Nodes:           make(map[string]*kbapi.NodeInfo),
+		Queues:          make(map[kbapi.QueueID]*kbapi.QueueInfo),
+		PriorityClasses: make(map[string]*v1beta1.PriorityClass),
+		errTasks:        workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		deletedJobs:     workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		defaultQueue:    dump.DefaultQueue,
+		// Events are dropped.
+		Recorder: &record.FakeRecorder{},
+	}
+
+	for _, pc := range dump.PriorityClasses {
+		sc.AddPriorityClass(pc)
+	}
+	for _, queue := range dump.Queues {
+		sc.AddQueue(queue)
+	}
+	for _, node := range dump.Nodes {
+		sc.AddNode(node)
+	}
+	for _, pg := range dump.PodGroups {
+		sc.AddPodGroup(pg)
+	}
+	for _, pod := range dump.Pods {
+		sc.AddPod(pod)
+	}
+
+	return sc
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/api/scheduling/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+)
+
+type fakeBinder struct {
+}
+
+func (fb *fakeBinder) Bind(p *v1.Pod, hostname string) error {
+	return nil
+}
+
+func TestClusterDump(t *testing.T) {
+	sc := &SchedulerCache{
+		Jobs:            make(map[api.JobID]*api.JobInfo),
+		Nodes:           make(map[string]*api.NodeInfo),
+		Queues:          make(map[api.QueueID]*api.QueueInfo),
+		PriorityClasses: make(map[string]*v1beta1.PriorityClass),
+		Binder:          &fakeBinder{},
+		Recorder:        &record.FakeRecorder{},
+		defaultQueue:    "q1",
+	}
+
+	sc.AddPriorityClass(&v1beta1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "high"},
+		Value:      100,
+	})
+	sc.AddQueue(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "q1"},
+		Spec:       kbv1.QueueSpec{Weight: 1},
+	})
+	sc.AddNode(buildNode("n1", buildResourceList("4", "4G")))
+	sc.AddPodGroup(&kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "c1", Name: "pg1"},
+		Spec: kbv1.PodGroupSpec{
+			MinMember:         2,
+			Queue:             "q1",
+			PriorityClassName: "high",
+		},
+	})
+
+	p1 := buildPod("c1", "p1", "n1", v1.PodRunning, buildResourceList("1", "1G"), nil, nil)
+	p2 := buildPod("c1", "p2", "", v1.PodPending, buildResourceList("1", "1G"), nil, nil)
+	for _, pod := range []*v1.Pod{p1, p2} {
+		pod.Annotations = map[string]string{kbv1.GroupNameAnnotationKey: "pg1"}
+		sc.AddPod(pod)
+	}
+	// p3 has no PodGroup, so it's in a shadow PodGroup of default queue.
+	p3 := buildPod("c1", "p3", "", v1.PodPending, buildResourceList("1", "1G"),
+		[]metav1.OwnerReference{buildOwnerReference("o1")}, nil)
+	sc.AddPod(p3)
+
+	// p2 is bound by scheduler, but its Pod is not updated yet.
+	if err := sc.Bind(sc.Jobs["c1/pg1"].Tasks["c1-p2"], "n1"); err != nil {
+		t.Fatalf("failed to bind p2: %v", err)
+	}
+
+	dump := sc.Dump()
+	if len(dump.Pods) != 3 || len(dump.PodGroups) != 1 || len(dump.Nodes) != 1 ||
+		len(dump.Queues) != 1 || len(dump.PriorityClasses) != 1 {
+		t.Fatalf("unexpected objects in dump: %d pods, %d podgroups, %d nodes, %d queues, %d priority classes",
+			len(dump.Pods), len(dump.PodGroups), len(dump.Nodes), len(dump.Queues), len(dump.PriorityClasses))
+	}
+	if dump.Pods[1].Name != "p2" || dump.Pods[1].Spec.NodeName != "n1" {
+		t.Errorf("expected the Pod of binding p2 on n1, got %s on <%s>", dump.Pods[1].Name, dump.Pods[1].Spec.NodeName)
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		data, err := EncodeClusterDump(dump, format)
+		if err != nil {
+			t.Fatalf("failed to encode dump as %s: %v", format, err)
+		}
+		decoded, err := DecodeClusterDump(data)
+		if err != nil {
+			t.Fatalf("failed to decode dump from %s: %v", format, err)
+		}
+
+		restoredCache := NewFromDump(decoded)
+		if pc := restoredCache.PriorityClasses["high"]; pc == nil || pc.Value != 100 {
+			t.Errorf("%s: expected priority class high of 100, got %v", format, pc)
+		}
+
+		restored := restoredCache.Snapshot()
+		if len(restored.Jobs) != 2 || len(restored.Nodes) != 1 || len(restored.Queues) != 1 {
+			t.Errorf("%s: unexpected snapshot restored: %v", format, restored)
+			continue
+		}
+
+		job := restored.Jobs["c1/pg1"]
+		if job == nil || job.MinAvailable != 2 {
+			t.Errorf("%s: expected job c1/pg1 with min available 2, got %v", format, job)
+		} else if len(job.TaskStatusIndex[api.Pending]) != 0 {
+			t.Errorf("%s: expected no pending task of c1/pg1, got %d", format, len(job.TaskStatusIndex[api.Pending]))
+		}
+
+		shadow := restored.Jobs["o1"]
+		if shadow == nil || shadow.Queue != "q1" {
+			t.Errorf("%s: expected shadow job o1 in default queue q1, got %v", format, shadow)
+		}
+
+		if n1 := restored.Nodes["n1"]; !reflect.DeepEqual(n1.Used, buildResource("2", "2G")) {
+			t.Errorf("%s: expected 2 tasks on n1, got used %v", format, n1.Used)
+		}
+	}
+
+	if _, err := DecodeClusterDump([]byte(`{"apiVersion": "v1", "kind": "ClusterDump"}`)); err == nil {
+		t.Errorf("expected error of unsupported version")
+	}
+}
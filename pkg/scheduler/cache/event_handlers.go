@@ -373,7 +373,8 @@ func (sc *SchedulerCache) setPodGroup(ss *kbv1.PodGroup) error {
 
 	sc.Jobs[job].SetPodGroup(ss)
 
-	// TODO(k82cn): set default queue in admission.
+	// The queue is defaulted by admission webhook; keep the fallback for
+	// clusters without it.
 	if len(ss.Spec.Queue) == 0 {
 		sc.Jobs[job].Queue = kbapi.QueueID(sc.defaultQueue)
 	}
@@ -661,7 +662,6 @@ func (sc *SchedulerCache) addQueue(queue *kbv1.Queue) error {
 }
 
 func (sc *SchedulerCache) updateQueue(oldObj, newObj *kbv1.Queue) error {
-	sc.deleteQueue(oldObj)
 	sc.addQueue(newObj)
 
 	return nil
@@ -669,11 +669,46 @@ func (sc *SchedulerCache) updateQueue(oldObj, newObj *kbv1.Queue) error {
 
 func (sc *SchedulerCache) deleteQueue(queue *kbv1.Queue) error {
 	qi := kbapi.NewQueueInfo(queue)
+
+	// Defer the deletion of Queue until all of its PodGroups are deleted; the Queue
+	// is closed in the meantime.
+	for _, job := range sc.Jobs {
+		if job.Queue == qi.UID {
+			glog.V(3).Infof("Queue <%s> still has PodGroups, defer its deletion.", qi.Name)
+
+			if queue.DeletionTimestamp == nil {
+				queue = queue.DeepCopy()
+				now := metav1.Now()
+				queue.DeletionTimestamp = &now
+			}
+			sc.addQueue(queue)
+
+			return nil
+		}
+	}
+
 	delete(sc.Queues, qi.UID)
 
 	return nil
 }
 
+// cleanupQueue deletes the Queue whose deletion was deferred, if it has no PodGroups.
+func (sc *SchedulerCache) cleanupQueue(queueID kbapi.QueueID) {
+	qi, found := sc.Queues[queueID]
+	if !found || qi.Queue.DeletionTimestamp == nil {
+		return
+	}
+
+	for _, job := range sc.Jobs {
+		if job.Queue == queueID {
+			return
+		}
+	}
+
+	glog.V(3).Infof("Queue <%s> was deleted.", qi.Name)
+	delete(sc.Queues, queueID)
+}
+
 func (sc *SchedulerCache) DeletePriorityClass(obj interface{}) {
 	var ss *v1beta1.PriorityClass
 	switch t := obj.(type) {
@@ -0,0 +1,468 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake provides an in-memory implementation of the scheduler Cache,
+// so actions and plugins can be tested against the real framework without
+// API server.
+package fake
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/api/scheduling/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	schedcache "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+)
+
+// DefaultQueue is the queue of the Pods without PodGroup in fake Cache; the
+// Queue itself has to be added as other objects.
+const DefaultQueue = "default"
+
+// Operation is the call of Cache to API server, which can fail by
+// InjectError.
+type Operation string
+
+const (
+	// Bind is the binding of task to node.
+	Bind Operation = "Bind"
+	// Evict is the eviction of task.
+	Evict Operation = "Evict"
+	// AllocateVolumes is the allocation of volumes of task on node.
+	AllocateVolumes Operation = "AllocateVolumes"
+	// BindVolumes is the binding of volumes of task.
+	BindVolumes Operation = "BindVolumes"
+	// UpdatePodCondition is the update of the condition of Pod, e.g. the
+	// unschedulable task.
+	UpdatePodCondition Operation = "UpdatePodCondition"
+	// UpdatePodGroup is the update of the status of PodGroup.
+	UpdatePodGroup Operation = "UpdatePodGroup"
+	// UpdateQueueStatus is the update of the status of Queue.
+	UpdateQueueStatus Operation = "UpdateQueueStatus"
+)
+
+// Cache is an in-memory Cache built from plain objects. Tasks are bound and
+// evicted at once by updating their Pods in cache: a bound Pod gets its node,
+// and an evicted Pod gets its deletion timestamp and is releasing until it's
+// deleted by Delete. Status updates are recorded, but not written back to
+// the objects in cache; call Update for that, as informers do.
+//
+// The calls are recorded by the key of object, "namespace/name" for Pods and
+// PodGroups and "name" for Queues.
+type Cache struct {
+	cache *schedcache.SchedulerCache
+
+	sync.Mutex
+	objects map[string]runtime.Object
+	errors  map[Operation]map[string]error
+
+	// Binds is the node of bound Pods.
+	Binds map[string]string
+	// Evictions is the reason of evicted Pods.
+	Evictions map[string]string
+	// PodConditions is the last condition updated of Pods.
+	PodConditions map[string]*v1.PodCondition
+	// PodGroups and Queues are the last status updated of PodGroups and
+	// Queues.
+	PodGroups map[string]*kbv1.PodGroup
+	Queues    map[string]*kbv1.Queue
+	// Events is the events recorded, in the format of "type reason message".
+	Events []string
+}
+
+var _ schedcache.Cache = &Cache{}
+
+// New returns a fake Cache with the objects; see Add.
+func New(objects ...runtime.Object) *Cache {
+	fc := &Cache{
+		cache:         schedcache.NewFromDump(&schedcache.ClusterDump{DefaultQueue: DefaultQueue}),
+		objects:       map[string]runtime.Object{},
+		errors:        map[Operation]map[string]error{},
+		Binds:         map[string]string{},
+		Evictions:     map[string]string{},
+		PodConditions: map[string]*v1.PodCondition{},
+		PodGroups:     map[string]*kbv1.PodGroup{},
+		Queues:        map[string]*kbv1.Queue{},
+	}
+
+	fc.cache.StatusUpdater = &statusUpdater{fc}
+	fc.cache.VolumeBinder = &volumeBinder{fc}
+	fc.cache.Recorder = &recorder{fc}
+
+	fc.Add(objects...)
+
+	return fc
+}
+
+// Add adds the objects to cache. Pod, Node, PodGroup, Queue, PriorityClass
+// and PodDisruptionBudget are supported; it panics for other objects. The
+// priority of Pod is 0 if not set, as the admission of API server does.
+func (fc *Cache) Add(objects ...runtime.Object) {
+	for _, obj := range objects {
+		if pod, ok := obj.(*v1.Pod); ok && pod.Spec.Priority == nil {
+			pod = pod.DeepCopy()
+			pod.Spec.Priority = new(int32)
+			obj = pod
+		}
+
+		fc.Lock()
+		fc.objects[objectKey(obj)] = obj
+		fc.Unlock()
+
+		switch obj.(type) {
+		case *v1.Pod:
+			fc.cache.AddPod(obj)
+		case *v1.Node:
+			fc.cache.AddNode(obj)
+		case *kbv1.PodGroup:
+			fc.cache.AddPodGroup(obj)
+		case *kbv1.Queue:
+			fc.cache.AddQueue(obj)
+		case *v1beta1.PriorityClass:
+			fc.cache.AddPriorityClass(obj)
+		case *policyv1.PodDisruptionBudget:
+			fc.cache.AddPDB(obj)
+		}
+	}
+}
+
+// Update replaces the object added before with the new one.
+func (fc *Cache) Update(obj runtime.Object) error {
+	key := objectKey(obj)
+
+	fc.Lock()
+	old, found := fc.objects[key]
+	if found {
+		fc.objects[key] = obj
+	}
+	fc.Unlock()
+
+	if !found {
+		return fmt.Errorf("failed to find object <%s> in fake cache", key)
+	}
+
+	switch obj.(type) {
+	case *v1.Pod:
+		fc.cache.UpdatePod(old, obj)
+	case *v1.Node:
+		fc.cache.UpdateNode(old, obj)
+	case *kbv1.PodGroup:
+		fc.cache.UpdatePodGroup(old, obj)
+	case *kbv1.Queue:
+		fc.cache.UpdateQueue(old, obj)
+	case *v1beta1.PriorityClass:
+		fc.cache.UpdatePriorityClass(old, obj)
+	case *policyv1.PodDisruptionBudget:
+		fc.cache.UpdatePDB(old, obj)
+	}
+
+	return nil
+}
+
+// Delete deletes the object added before.
+func (fc *Cache) Delete(obj runtime.Object) error {
+	key := objectKey(obj)
+
+	fc.Lock()
+	old, found := fc.objects[key]
+	delete(fc.objects, key)
+	fc.Unlock()
+
+	if !found {
+		return fmt.Errorf("failed to find object <%s> in fake cache", key)
+	}
+
+	switch old.(type) {
+	case *v1.Pod:
+		fc.cache.DeletePod(old)
+	case *v1.Node:
+		fc.cache.DeleteNode(old)
+	case *kbv1.PodGroup:
+		fc.cache.DeletePodGroup(old)
+	case *kbv1.Queue:
+		fc.cache.DeleteQueue(old)
+	case *v1beta1.PriorityClass:
+		fc.cache.DeletePriorityClass(old)
+	case *policyv1.PodDisruptionBudget:
+		fc.cache.DeletePDB(old)
+	}
+
+	return nil
+}
+
+// Get returns the object of the kind and key in cache, e.g. the Pod updated
+// by Bind; or nil if not found.
+func (fc *Cache) Get(kind runtime.Object, key string) runtime.Object {
+	fc.Lock()
+	defer fc.Unlock()
+
+	return fc.objects[fmt.Sprintf("%T:%s", kind, key)]
+}
+
+// InjectError makes the operation on the object of key fail with err; key
+// "" is of all objects. A nil err clears the failure.
+func (fc *Cache) InjectError(op Operation, key string, err error) {
+	fc.Lock()
+	defer fc.Unlock()
+
+	if err == nil {
+		delete(fc.errors[op], key)
+		return
+	}
+
+	if fc.errors[op] == nil {
+		fc.errors[op] = map[string]error{}
+	}
+	fc.errors[op][key] = err
+}
+
+// injectedError returns the error injected for the operation on the object
+// of key; it's called with lock held.
+func (fc *Cache) injectedError(op Operation, key string) error {
+	if err, found := fc.errors[op][key]; found {
+		return err
+	}
+	return fc.errors[op][""]
+}
+
+func objectKey(obj runtime.Object) string {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		panic(fmt.Sprintf("unsupported object %T in fake cache: %v", obj, err))
+	}
+
+	switch obj.(type) {
+	case *v1.Pod, *v1.Node, *kbv1.PodGroup, *kbv1.Queue, *v1beta1.PriorityClass, *policyv1.PodDisruptionBudget:
+	default:
+		panic(fmt.Sprintf("unsupported object %T in fake cache", obj))
+	}
+
+	return fmt.Sprintf("%T:%s", obj, key)
+}
+
+func podKey(pod *v1.Pod) string {
+	return fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+}
+
+// Run does nothing, there's no informer.
+func (fc *Cache) Run(stopCh <-chan struct{}) {}
+
+// WaitForCacheSync returns true at once, the objects are added to cache
+// synchronously.
+func (fc *Cache) WaitForCacheSync(stopCh <-chan struct{}) bool {
+	return true
+}
+
+// Snapshot deep copy overall cache information into snapshot
+func (fc *Cache) Snapshot() *api.ClusterInfo {
+	return fc.cache.Snapshot()
+}
+
+// Dump returns the snapshot of cache in serializable form.
+func (fc *Cache) Dump() *schedcache.ClusterDump {
+	return fc.cache.Dump()
+}
+
+// Bind binds the task to the host by setting the node of its Pod.
+func (fc *Cache) Bind(task *api.TaskInfo, hostname string) error {
+	key := podKey(task.Pod)
+
+	fc.Lock()
+	if err := fc.injectedError(Bind, key); err != nil {
+		fc.Unlock()
+		return err
+	}
+	fc.Binds[key] = hostname
+	fc.Unlock()
+
+	pod := fc.Get(&v1.Pod{}, key)
+	if pod == nil {
+		return fmt.Errorf("failed to bind Task %v to host %v, pod does not exist", task.UID, hostname)
+	}
+
+	newPod := pod.(*v1.Pod).DeepCopy()
+	newPod.Spec.NodeName = hostname
+	if err := fc.Update(newPod); err != nil {
+		return err
+	}
+
+	fc.cache.Recorder.Eventf(newPod, v1.EventTypeNormal, "Scheduled", "Successfully assigned %v/%v to %v", newPod.Namespace, newPod.Name, hostname)
+
+	return nil
+}
+
+// Evict evicts the task by setting the deletion timestamp of its Pod.
+func (fc *Cache) Evict(task *api.TaskInfo, reason string) error {
+	key := podKey(task.Pod)
+
+	fc.Lock()
+	if err := fc.injectedError(Evict, key); err != nil {
+		fc.Unlock()
+		return err
+	}
+	fc.Evictions[key] = reason
+	fc.Unlock()
+
+	pod := fc.Get(&v1.Pod{}, key)
+	if pod == nil {
+		return fmt.Errorf("failed to evict Task %v, pod does not exist", task.UID)
+	}
+
+	newPod := pod.(*v1.Pod).DeepCopy()
+	now := metav1.Now()
+	newPod.DeletionTimestamp = &now
+	if err := fc.Update(newPod); err != nil {
+		return err
+	}
+
+	fc.cache.Recorder.Event(newPod, v1.EventTypeNormal, "Evict", reason)
+
+	return nil
+}
+
+// RecordJobStatusEvent records related events according to job status.
+func (fc *Cache) RecordJobStatusEvent(job *api.JobInfo) {
+	fc.cache.RecordJobStatusEvent(job)
+}
+
+// RecordEvent records an event of kube-batch itself.
+func (fc *Cache) RecordEvent(eventType, reason, message string) {
+	fc.Lock()
+	defer fc.Unlock()
+
+	fc.Events = append(fc.Events, fmt.Sprintf("%s %s %s", eventType, reason, message))
+}
+
+// UpdateJobStatus update the status of job and its tasks.
+func (fc *Cache) UpdateJobStatus(job *api.JobInfo) (*api.JobInfo, error) {
+	return fc.cache.UpdateJobStatus(job)
+}
+
+// UpdateQueueStatus update the status of queue if it's changed.
+func (fc *Cache) UpdateQueueStatus(queue *api.QueueInfo) error {
+	return fc.cache.UpdateQueueStatus(queue)
+}
+
+// AllocateVolumes allocates volume on the host to the task
+func (fc *Cache) AllocateVolumes(task *api.TaskInfo, hostname string) error {
+	return fc.cache.AllocateVolumes(task, hostname)
+}
+
+// RevertVolumes reverts the volumes allocated to the task
+func (fc *Cache) RevertVolumes(task *api.TaskInfo) {
+	fc.cache.RevertVolumes(task)
+}
+
+// BindVolumes binds volumes to the task
+func (fc *Cache) BindVolumes(task *api.TaskInfo) error {
+	return fc.cache.BindVolumes(task)
+}
+
+// statusUpdater records the status updates of SchedulerCache in fake Cache.
+type statusUpdater struct {
+	fc *Cache
+}
+
+func (su *statusUpdater) UpdatePodCondition(pod *v1.Pod, condition *v1.PodCondition) (*v1.Pod, error) {
+	su.fc.Lock()
+	defer su.fc.Unlock()
+
+	key := podKey(pod)
+	if err := su.fc.injectedError(UpdatePodCondition, key); err != nil {
+		return nil, err
+	}
+	su.fc.PodConditions[key] = condition.DeepCopy()
+
+	return pod, nil
+}
+
+func (su *statusUpdater) UpdatePodGroup(pg *kbv1.PodGroup) (*kbv1.PodGroup, error) {
+	su.fc.Lock()
+	defer su.fc.Unlock()
+
+	key := fmt.Sprintf("%s/%s", pg.Namespace, pg.Name)
+	if err := su.fc.injectedError(UpdatePodGroup, key); err != nil {
+		return nil, err
+	}
+	su.fc.PodGroups[key] = pg.DeepCopy()
+
+	return pg, nil
+}
+
+func (su *statusUpdater) UpdateQueueStatus(queue *kbv1.Queue) (*kbv1.Queue, error) {
+	su.fc.Lock()
+	defer su.fc.Unlock()
+
+	if err := su.fc.injectedError(UpdateQueueStatus, queue.Name); err != nil {
+		return nil, err
+	}
+	su.fc.Queues[queue.Name] = queue.DeepCopy()
+
+	return queue, nil
+}
+
+// volumeBinder binds no volume, but fails as injected.
+type volumeBinder struct {
+	fc *Cache
+}
+
+func (vb *volumeBinder) AllocateVolumes(task *api.TaskInfo, hostname string) error {
+	vb.fc.Lock()
+	defer vb.fc.Unlock()
+
+	return vb.fc.injectedError(AllocateVolumes, podKey(task.Pod))
+}
+
+func (vb *volumeBinder) RevertVolumes(task *api.TaskInfo) {
+}
+
+func (vb *volumeBinder) BindVolumes(task *api.TaskInfo) error {
+	vb.fc.Lock()
+	defer vb.fc.Unlock()
+
+	return vb.fc.injectedError(BindVolumes, podKey(task.Pod))
+}
+
+// recorder records the events of SchedulerCache in fake Cache.
+type recorder struct {
+	fc *Cache
+}
+
+func (r *recorder) Event(object runtime.Object, eventType, reason, message string) {
+	r.fc.Lock()
+	defer r.fc.Unlock()
+
+	r.fc.Events = append(r.fc.Events, fmt.Sprintf("%s %s %s", eventType, reason, message))
+}
+
+func (r *recorder) Eventf(object runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *recorder) PastEventf(object runtime.Object, timestamp metav1.Time, eventType, reason, messageFmt string, args ...interface{}) {
+	r.Eventf(object, eventType, reason, messageFmt, args...)
+}
+
+func (r *recorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventType, reason, messageFmt string, args ...interface{}) {
+	r.Eventf(object, eventType, reason, messageFmt, args...)
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions/allocate"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/gang"
+)
+
+func buildNode(name, cpu string) *v1.Node {
+	alloc := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse("4Gi"),
+		v1.ResourcePods:   resource.MustParse("10"),
+	}
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Capacity:    alloc,
+			Allocatable: alloc,
+		},
+	}
+}
+
+func buildPod(name, group, cpu string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:         types.UID("c1-" + name),
+			Name:        name,
+			Namespace:   "c1",
+			Annotations: map[string]string{kbv1.GroupNameAnnotationKey: group},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+				},
+			}},
+		},
+		Status: v1.PodStatus{Phase: v1.PodPending},
+	}
+}
+
+func buildPodGroup(name string, minMember int32) *kbv1.PodGroup {
+	return &kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "c1"},
+		Spec:       kbv1.PodGroupSpec{MinMember: minMember, Queue: DefaultQueue},
+		Status:     kbv1.PodGroupStatus{Phase: kbv1.PodGroupInQueue},
+	}
+}
+
+func runAllocate(fc *Cache) {
+	ssn := framework.OpenSession(fc, []conf.Tier{{
+		Plugins: []conf.PluginOption{{Name: "gang"}},
+	}})
+	defer framework.CloseSession(ssn)
+
+	allocate.New().Execute(ssn)
+}
+
+func TestCache(t *testing.T) {
+	framework.RegisterPluginBuilder("gang", gang.New)
+	defer framework.CleanupPluginBuilders()
+
+	fc := New(
+		&kbv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: DefaultQueue},
+			Spec:       kbv1.QueueSpec{Weight: 1},
+		},
+		buildNode("n1", "2"),
+		buildNode("n2", "2"),
+		buildPodGroup("pg1", 2),
+		buildPod("p1", "pg1", "1"),
+		buildPod("p2", "pg1", "1"),
+		buildPodGroup("pg2", 1),
+		buildPod("p3", "pg2", "2"),
+		buildPodGroup("pg3", 1),
+		buildPod("p4", "pg3", "4"),
+	)
+
+	// p3 fails to be bound, and p4 never fits.
+	fc.InjectError(Bind, "c1/p3", fmt.Errorf("injected"))
+	runAllocate(fc)
+
+	if len(fc.Binds) != 2 || len(fc.Binds["c1/p1"]) == 0 || len(fc.Binds["c1/p2"]) == 0 {
+		t.Errorf("expected p1 and p2 are bound, got %v", fc.Binds)
+	}
+	if cond := fc.PodConditions["c1/p4"]; cond == nil || cond.Reason != v1.PodReasonUnschedulable {
+		t.Errorf("expected p4 is unschedulable, got %v", cond)
+	}
+	if pg := fc.PodGroups["c1/pg1"]; pg == nil || pg.Status.Phase != kbv1.PodGroupRunning {
+		t.Errorf("expected pg1 is running, got %v", pg)
+	}
+	if q := fc.Queues[DefaultQueue]; q == nil || q.Status.Running != 1 {
+		t.Errorf("expected one running job in queue, got %v", q)
+	}
+
+	pod := fc.Get(&v1.Pod{}, "c1/p1").(*v1.Pod)
+	if pod.Spec.NodeName != fc.Binds["c1/p1"] {
+		t.Errorf("expected node of p1 is <%s>, got <%s>", fc.Binds["c1/p1"], pod.Spec.NodeName)
+	}
+
+	// p3 is bound once the failure is cleared.
+	fc.InjectError(Bind, "c1/p3", nil)
+	runAllocate(fc)
+
+	if len(fc.Binds["c1/p3"]) == 0 {
+		t.Errorf("expected p3 is bound, got %v", fc.Binds)
+	}
+
+	snapshot := fc.Snapshot()
+	task := snapshot.Jobs[api.JobID("c1/pg1")].Tasks[api.TaskID("c1-p1")]
+	if err := fc.Evict(task, "preempted"); err != nil {
+		t.Fatalf("failed to evict p1: %v", err)
+	}
+	if !reflect.DeepEqual(fc.Evictions, map[string]string{"c1/p1": "preempted"}) {
+		t.Errorf("expected p1 is evicted, got %v", fc.Evictions)
+	}
+	task = fc.Snapshot().Jobs[api.JobID("c1/pg1")].Tasks[api.TaskID("c1-p1")]
+	if task.Status != api.Releasing {
+		t.Errorf("expected p1 is releasing, got %v", task.Status)
+	}
+
+	fc.InjectError(Evict, "", fmt.Errorf("injected"))
+	task = fc.Snapshot().Jobs[api.JobID("c1/pg1")].Tasks[api.TaskID("c1-p2")]
+	if err := fc.Evict(task, "preempted"); err == nil {
+		t.Errorf("expected injected error of evicting p2")
+	}
+
+	if err := fc.Delete(fc.Get(&v1.Pod{}, "c1/p1")); err != nil {
+		t.Fatalf("failed to delete p1: %v", err)
+	}
+	if _, found := fc.Snapshot().Jobs[api.JobID("c1/pg1")].Tasks[api.TaskID("c1-p1")]; found {
+		t.Errorf("expected p1 is deleted")
+	}
+}
+
+func TestQueueStatus(t *testing.T) {
+	framework.RegisterPluginBuilder("gang", gang.New)
+	defer framework.CleanupPluginBuilders()
+
+	// pg1 is invalid as it has not enough tasks, but still pending in queue.
+	pg1 := buildPodGroup("pg1", 2)
+	pg1.Status.Phase = kbv1.PodGroupPending
+	fc := New(
+		&kbv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: DefaultQueue},
+			Spec:       kbv1.QueueSpec{Weight: 1},
+		},
+		buildNode("n1", "2"),
+		pg1,
+		buildPod("p1", "pg1", "1"),
+	)
+	runAllocate(fc)
+
+	if q := fc.Queues[DefaultQueue]; q == nil || q.Status.Pending != 1 {
+		t.Errorf("expected one pending job in queue, got %v", q)
+	}
+}
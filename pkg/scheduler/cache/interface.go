@@ -32,6 +32,10 @@ type Cache interface {
 	// Snapshot deep copy overall cache information into snapshot
 	Snapshot() *api.ClusterInfo
 
+	// Dump returns the snapshot of cache in serializable form, which can be
+	// loaded by NewFromDump.
+	Dump() *ClusterDump
+
 	// WaitForCacheSync waits for all cache synced
 	WaitForCacheSync(stopCh <-chan struct{}) bool
 
@@ -46,18 +50,29 @@ type Cache interface {
 	// Deprecated: remove it after removed PDB support.
 	RecordJobStatusEvent(job *api.JobInfo)
 
+	// RecordEvent records an event of kube-batch itself, e.g. failed to
+	// reload scheduler configuration.
+	RecordEvent(eventType, reason, message string)
+
 	// UpdateJobStatus puts job in backlog for a while.
 	UpdateJobStatus(job *api.JobInfo) (*api.JobInfo, error)
 
+	// UpdateQueueStatus updates the status of queue.
+	UpdateQueueStatus(queue *api.QueueInfo) error
+
 	// AllocateVolumes allocates volume on the host to the task
 	AllocateVolumes(task *api.TaskInfo, hostname string) error
 
+	// RevertVolumes reverts the volumes allocated to the task
+	RevertVolumes(task *api.TaskInfo)
+
 	// BindVolumes binds volumes to the task
 	BindVolumes(task *api.TaskInfo) error
 }
 
 type VolumeBinder interface {
 	AllocateVolumes(task *api.TaskInfo, hostname string) error
+	RevertVolumes(task *api.TaskInfo)
 	BindVolumes(task *api.TaskInfo) error
 }
 
@@ -73,4 +88,5 @@ type Evictor interface {
 type StatusUpdater interface {
 	UpdatePodCondition(pod *v1.Pod, podCondition *v1.PodCondition) (*v1.Pod, error)
 	UpdatePodGroup(pg *v1alpha1.PodGroup) (*v1alpha1.PodGroup, error)
+	UpdateQueueStatus(queue *v1alpha1.Queue) (*v1alpha1.Queue, error)
 }
@@ -16,14 +16,55 @@ limitations under the License.
 
 package conf
 
+import (
+	"strings"
+	"time"
+)
+
 // SchedulerConfiguration defines the configuration of scheduler.
 type SchedulerConfiguration struct {
 	// Actions defines the actions list of scheduler in order
-	Actions string `yaml:"actions"`
+	Actions ActionOptions `yaml:"actions"`
 	// Tiers defines plugins in different tiers
 	Tiers []Tier `yaml:"tiers"`
 }
 
+// ActionOptions defines the actions list of scheduler in order; besides the
+// list, it also accepts a comma-separated string of action names, e.g.
+// "allocate, backfill".
+type ActionOptions []ActionOption
+
+// UnmarshalYAML unmarshals ActionOptions from a list or a string.
+func (a *ActionOptions) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var names string
+	if err := unmarshal(&names); err == nil {
+		*a = nil
+		for _, name := range strings.Split(names, ",") {
+			*a = append(*a, ActionOption{Name: strings.TrimSpace(name)})
+		}
+		return nil
+	}
+
+	var options []ActionOption
+	if err := unmarshal(&options); err != nil {
+		return err
+	}
+	*a = options
+
+	return nil
+}
+
+// ActionOption defines the options of action
+type ActionOption struct {
+	// The name of Action
+	Name string `yaml:"name"`
+	// Arguments defines the different arguments that can be given to different actions
+	Arguments map[string]string `yaml:"arguments"`
+	// Interval defines the minimum interval between two runs of the action,
+	// e.g. "30s"; the action runs in every scheduling cycle if not set
+	Interval time.Duration `yaml:"interval"`
+}
+
 // Tier defines plugin tier
 type Tier struct {
 	Plugins []PluginOption `yaml:"plugins"`
@@ -49,6 +90,9 @@ type PluginOption struct {
 	PredicateDisabled bool `yaml:"disablePredicate"`
 	// NodeOrderDisabled defines whether NodeOrderFn is disabled
 	NodeOrderDisabled bool `yaml:"disableNodeOrder"`
+	// NodeOrderWeight defines the multiplier of the normalized node scores of
+	// the plugin; 1 if not set
+	NodeOrderWeight int `yaml:"nodeOrderWeight"`
 	// Arguments defines the different arguments that can be given to different plugins
 	Arguments map[string]string `yaml:"arguments"`
 }
@@ -0,0 +1,52 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"net/http"
+
+	schedcache "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+)
+
+// DumpPath is the path of HTTP endpoint which dumps the snapshot of cluster.
+const DumpPath = "/debug/dump"
+
+// DumpHandler returns the handler of dump endpoint, which serves the snapshot
+// of scheduler cache in the format given by `format=json|yaml`, JSON by
+// default; it can be replayed by `kube-batch replay`.
+func (pc *Scheduler) DumpHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		switch format {
+		case "":
+			format = "json"
+		case "json", "yaml":
+		default:
+			http.Error(w, "format=json|yaml is supported", http.StatusBadRequest)
+			return
+		}
+
+		data, err := schedcache.EncodeClusterDump(pc.cache.Dump(), format)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/"+format)
+		w.Write(data)
+	})
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+// ExplainPath is the path of HTTP endpoint which explains the scheduling
+// decisions of a PodGroup.
+const ExplainPath = "/debug/explain"
+
+// explanation is the response of explain endpoint.
+type explanation struct {
+	SessionUID  types.UID             `json:"sessionUID"`
+	SessionTime time.Time             `json:"sessionTime"`
+	PodGroup    *framework.JobTrace   `json:"podGroup"`
+	Queue       *framework.QueueTrace `json:"queue,omitempty"`
+}
+
+// ExplainHandler returns the handler of explain endpoint, which serves the
+// scheduling decisions of the PodGroup given by `podgroup=<namespace>/<name>`
+// in the latest session as JSON.
+func (pc *Scheduler) ExplainHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("podgroup")
+		if parts := strings.Split(key, "/"); len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			http.Error(w, "podgroup=<namespace>/<name> is required", http.StatusBadRequest)
+			return
+		}
+
+		pc.mutex.Lock()
+		trace := pc.trace
+		pc.mutex.Unlock()
+
+		if trace == nil {
+			http.Error(w, "no scheduling session is finished yet", http.StatusServiceUnavailable)
+			return
+		}
+
+		jt, found := trace.Job(key)
+		if !found {
+			http.Error(w, fmt.Sprintf("PodGroup <%s> is not found in session <%s>", key, trace.SessionUID),
+				http.StatusNotFound)
+			return
+		}
+
+		result := &explanation{
+			SessionUID:  trace.SessionUID,
+			SessionTime: trace.StartTime,
+			PodGroup:    jt,
+		}
+		if qt, found := trace.Queue(jt.Queue); found {
+			result.Queue = qt
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+func TestExplainHandler(t *testing.T) {
+	pc := &Scheduler{}
+	server := httptest.NewServer(pc.ExplainHandler())
+	defer server.Close()
+
+	get := func(query string) (*http.Response, *explanation) {
+		resp, err := http.Get(server.URL + ExplainPath + query)
+		if err != nil {
+			t.Fatalf("failed to get %s: %v", query, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return resp, nil
+		}
+		result := &explanation{}
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			t.Fatalf("failed to decode explanation: %v", err)
+		}
+		return resp, result
+	}
+
+	if resp, _ := get("?podgroup=c1/pg1"); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected %d before any session, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+
+	pc.trace = &framework.Trace{
+		SessionUID: "s1",
+		Jobs: map[string]*framework.JobTrace{
+			"c1/pg1": {
+				Namespace:         "c1",
+				Name:              "pg1",
+				Queue:             "q1",
+				PredicateFailures: map[string]string{"n1": "node <n1> is reserved"},
+				MinAvailable:      2,
+			},
+		},
+		Queues: map[string]*framework.QueueTrace{
+			"q1": {Overused: true},
+		},
+	}
+
+	for query, code := range map[string]int{
+		"":                  http.StatusBadRequest,
+		"?podgroup=pg1":     http.StatusBadRequest,
+		"?podgroup=c1/pg2":  http.StatusNotFound,
+		"?podgroup=c1/pg1/": http.StatusBadRequest,
+	} {
+		if resp, _ := get(query); resp.StatusCode != code {
+			t.Errorf("query %q: expected %d, got %d", query, code, resp.StatusCode)
+		}
+	}
+
+	resp, result := get("?podgroup=c1/pg1")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if result.SessionUID != "s1" || result.PodGroup.Name != "pg1" ||
+		result.PodGroup.PredicateFailures["n1"] != "node <n1> is reserved" {
+		t.Errorf("unexpected explanation of pg1: %+v", result.PodGroup)
+	}
+	if result.Queue == nil || !result.Queue.Overused {
+		t.Errorf("expected queue q1 is overused, got %+v", result.Queue)
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Arguments are the arguments given to an action or a plugin in scheduler
+// configuration.
+type Arguments map[string]string
+
+// GetInt sets ptr to the int value of key; ptr is unchanged if the key is not
+// given or its value is not an int.
+func (a Arguments) GetInt(ptr *int, key string) {
+	if ptr == nil {
+		return
+	}
+
+	value, found := a[key]
+	if !found || len(value) == 0 {
+		return
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		glog.Warningf("Could not parse argument %s: %s as int, %v", key, value, err)
+		return
+	}
+
+	*ptr = v
+}
+
+// ArgumentType is the type of the value of plugin argument.
+type ArgumentType string
+
+const (
+	// ArgumentInt is an argument of integer, e.g. "10"
+	ArgumentInt ArgumentType = "int"
+	// ArgumentBool is an argument of boolean, e.g. "true"
+	ArgumentBool ArgumentType = "bool"
+	// ArgumentString is an argument of any string
+	ArgumentString ArgumentType = "string"
+	// ArgumentDuration is an argument of duration, e.g. "5s"
+	ArgumentDuration ArgumentType = "duration"
+)
+
+// ArgumentSchema declares the arguments accepted by a plugin, by argument key.
+type ArgumentSchema map[string]ArgumentType
+
+// Validate checks that all arguments are declared by the schema and their
+// values are of the declared type; it returns one error per invalid argument.
+func (s ArgumentSchema) Validate(arguments map[string]string) []error {
+	var keys []string
+	for key := range arguments {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		value := arguments[key]
+
+		argType, found := s[key]
+		if !found {
+			errs = append(errs, fmt.Errorf("unknown argument %q", key))
+			continue
+		}
+
+		var err error
+		switch argType {
+		case ArgumentInt:
+			_, err = strconv.Atoi(value)
+		case ArgumentBool:
+			_, err = strconv.ParseBool(value)
+		case ArgumentDuration:
+			_, err = time.ParseDuration(value)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("argument %q: invalid %s value %q", key, argType, value))
+		}
+	}
+
+	return errs
+}
+
+var argumentSchemas = map[string]ArgumentSchema{}
+
+var actionArgumentSchemas = map[string]ArgumentSchema{}
+
+// RegisterPluginArguments registers the argument schema of a plugin; the
+// arguments of plugins without schema are not validated.
+func RegisterPluginArguments(name string, schema ArgumentSchema) {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	argumentSchemas[name] = schema
+}
+
+// GetPluginArguments gets the argument schema of a plugin.
+func GetPluginArguments(name string) (ArgumentSchema, bool) {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	schema, found := argumentSchemas[name]
+	return schema, found
+}
+
+// RegisterActionArguments registers the argument schema of an action; the
+// arguments of actions without schema are not validated.
+func RegisterActionArguments(name string, schema ArgumentSchema) {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	actionArgumentSchemas[name] = schema
+}
+
+// GetActionArguments gets the argument schema of an action.
+func GetActionArguments(name string) (ArgumentSchema, bool) {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	schema, found := actionArgumentSchemas[name]
+	return schema, found
+}
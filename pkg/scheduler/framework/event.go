@@ -20,11 +20,56 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
 )
 
+// EventType is the operation which fires the event.
+type EventType string
+
+const (
+	// PipelineEvent is fired when a task is pipelined onto a node.
+	PipelineEvent EventType = "Pipeline"
+	// UnpipelineEvent is fired when a pipelined task is discarded by statement.
+	UnpipelineEvent EventType = "Unpipeline"
+	// AllocateEvent is fired when a task is allocated onto a node.
+	AllocateEvent EventType = "Allocate"
+	// UnallocateEvent is fired when an allocated task is discarded by statement
+	// or failed to bind on commit.
+	UnallocateEvent EventType = "Unallocate"
+	// DispatchEvent is fired when a task is bound to its node.
+	DispatchEvent EventType = "Dispatch"
+	// BindFailedEvent is fired when a task failed to bind to its node.
+	BindFailedEvent EventType = "BindFailed"
+	// EvictEvent is fired when a task is evicted.
+	EvictEvent EventType = "Evict"
+	// UnevictEvent is fired when an eviction is discarded by statement or failed.
+	UnevictEvent EventType = "Unevict"
+	// JobReadyEvent is fired when a job becomes ready by allocation; Task is the
+	// one whose allocation makes the job ready.
+	JobReadyEvent EventType = "JobReady"
+)
+
+// Event is the information of an operation in session.
 type Event struct {
+	Type EventType
 	Task *api.TaskInfo
+	// Job is the job of Task, nil if it's not found in session.
+	Job *api.JobInfo
+	// NodeName is the node involved by the operation.
+	NodeName string
+	// Reason is why the operation happened, e.g. the reason of eviction
+	// or the message of bind failure.
+	Reason string
+	// Err is the error of failed operation, e.g. BindFailedEvent.
+	Err error
 }
 
+// EventHandler is the callbacks of session events.
 type EventHandler struct {
-	AllocateFunc   func(event *Event)
+	// AllocateFunc is called when the resource of a task is occupied on
+	// node, i.e. PipelineEvent, AllocateEvent and UnevictEvent.
+	AllocateFunc func(event *Event)
+	// DeallocateFunc is called when the resource of a task is released on
+	// node, i.e. EvictEvent, UnpipelineEvent and UnallocateEvent.
 	DeallocateFunc func(event *Event)
+
+	// EventFuncs are called for the events of the given type.
+	EventFuncs map[EventType]func(event *Event)
 }
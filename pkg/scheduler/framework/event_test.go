@@ -0,0 +1,290 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+)
+
+type fakeBinder struct {
+}
+
+func (fb *fakeBinder) Bind(p *v1.Pod, hostname string) error {
+	return nil
+}
+
+type fakeStatusUpdater struct {
+}
+
+func (ftsu *fakeStatusUpdater) UpdatePodCondition(pod *v1.Pod, podCondition *v1.PodCondition) (*v1.Pod, error) {
+	// do nothing here
+	return nil, nil
+}
+
+func (ftsu *fakeStatusUpdater) UpdatePodGroup(pg *kbv1.PodGroup) (*kbv1.PodGroup, error) {
+	// do nothing here
+	return nil, nil
+}
+
+func (ftsu *fakeStatusUpdater) UpdateQueueStatus(queue *kbv1.Queue) (*kbv1.Queue, error) {
+	// do nothing here
+	return queue, nil
+}
+
+// fakeVolumeBinder fails to bind volumes of the pods in failed, and records
+// the pods whose volumes are reverted.
+type fakeVolumeBinder struct {
+	failed   map[string]bool
+	reverted []string
+}
+
+func (fvb *fakeVolumeBinder) AllocateVolumes(task *api.TaskInfo, hostname string) error {
+	return nil
+}
+
+func (fvb *fakeVolumeBinder) RevertVolumes(task *api.TaskInfo) {
+	fvb.reverted = append(fvb.reverted, task.Name)
+}
+
+func (fvb *fakeVolumeBinder) BindVolumes(task *api.TaskInfo) error {
+	if fvb.failed[task.Name] {
+		return fmt.Errorf("failed to bind volumes of %s", task.Name)
+	}
+	return nil
+}
+
+// eventRecorderPlugin records the events of session, and makes jobs ready
+// by their min member.
+type eventRecorderPlugin struct {
+	events []string
+}
+
+func (erp *eventRecorderPlugin) Name() string {
+	return "eventRecorder"
+}
+
+func (erp *eventRecorderPlugin) OnSessionOpen(ssn *Session) {
+	ssn.AddJobReadyFn(erp.Name(), func(obj interface{}) api.JobReadiness {
+		return obj.(*api.JobInfo).GetReadiness()
+	})
+
+	record := func(event *Event) {
+		msg := fmt.Sprintf("%s %s/%s", event.Type, event.Job.Name, event.Task.Name)
+		if len(event.NodeName) != 0 {
+			msg += " on " + event.NodeName
+		}
+		if len(event.Reason) != 0 {
+			msg += ": " + event.Reason
+		}
+		erp.events = append(erp.events, msg)
+	}
+
+	eventFuncs := map[EventType]func(event *Event){}
+	for _, t := range []EventType{PipelineEvent, UnpipelineEvent, AllocateEvent, UnallocateEvent, DispatchEvent,
+		BindFailedEvent, EvictEvent, UnevictEvent, JobReadyEvent} {
+		eventFuncs[t] = record
+	}
+
+	ssn.AddEventHandler(&EventHandler{
+		AllocateFunc: func(event *Event) {
+			erp.events = append(erp.events, "allocated "+event.Task.Name)
+		},
+		DeallocateFunc: func(event *Event) {
+			erp.events = append(erp.events, "deallocated "+event.Task.Name)
+		},
+		EventFuncs: eventFuncs,
+	})
+}
+
+func (erp *eventRecorderPlugin) OnSessionClose(ssn *Session) {}
+
+func buildPod(ns, n, groupName string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       types.UID(fmt.Sprintf("%v-%v", ns, n)),
+			Name:      n,
+			Namespace: ns,
+			Annotations: map[string]string{
+				kbv1.GroupNameAnnotationKey: groupName,
+			},
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodPending,
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse("1"),
+							v1.ResourceMemory: resource.MustParse("1G"),
+						},
+					},
+				},
+			},
+			Priority: new(int32),
+		},
+	}
+}
+
+func buildPodGroup(ns, n string, minMember int32) *kbv1.PodGroup {
+	return &kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      n,
+			Namespace: ns,
+		},
+		Spec: kbv1.PodGroupSpec{
+			Queue:     "c1",
+			MinMember: minMember,
+		},
+	}
+}
+
+// openTestSession opens a session with node n1 of 4 CPUs, job pg1 of p1, p2
+// with min member 2, and job pg2 of p3, p4 with min member 1; volumes of p4
+// fail to bind.
+func openTestSession(plugin Plugin) *Session {
+	RegisterPluginBuilder(plugin.Name(), func(map[string]string) Plugin { return plugin })
+
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Queues:        make(map[api.QueueID]*api.QueueInfo),
+		Binder:        &fakeBinder{},
+		StatusUpdater: &fakeStatusUpdater{},
+		VolumeBinder:  &fakeVolumeBinder{failed: map[string]bool{"p4": true}},
+
+		Recorder: record.NewFakeRecorder(100),
+	}
+	schedulerCache.AddNode(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("4"),
+				v1.ResourceMemory: resource.MustParse("8G"),
+			},
+		},
+	})
+	schedulerCache.AddQueue(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{Name: "c1"},
+		Spec:       kbv1.QueueSpec{Weight: 1},
+	})
+	schedulerCache.AddPodGroup(buildPodGroup("c1", "pg1", 2))
+	schedulerCache.AddPodGroup(buildPodGroup("c1", "pg2", 1))
+	for _, pod := range []*v1.Pod{
+		buildPod("c1", "p1", "pg1"),
+		buildPod("c1", "p2", "pg1"),
+		buildPod("c1", "p3", "pg2"),
+		buildPod("c1", "p4", "pg2"),
+	} {
+		schedulerCache.AddPod(pod)
+	}
+
+	return OpenSession(schedulerCache, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name: plugin.Name(),
+				},
+			},
+		},
+	})
+}
+
+// sessionTasks returns the tasks of session by name.
+func sessionTasks(ssn *Session) map[string]*api.TaskInfo {
+	tasks := map[string]*api.TaskInfo{}
+	for _, job := range ssn.Jobs {
+		for _, task := range job.Tasks {
+			tasks[task.Name] = task
+		}
+	}
+	return tasks
+}
+
+func TestSessionEvents(t *testing.T) {
+	erp := &eventRecorderPlugin{}
+	defer CleanupPluginBuilders()
+
+	ssn := openTestSession(erp)
+	defer CloseSession(ssn)
+
+	tasks := sessionTasks(ssn)
+	// Job pg1 is ready and dispatched by the allocation of its second task.
+	for _, name := range []string{"p1", "p2"} {
+		if err := ssn.Allocate(tasks[name], "n1", false); err != nil {
+			t.Fatalf("failed to allocate %s: %v", name, err)
+		}
+	}
+
+	// Eviction of statement is undone by discard.
+	stmt := ssn.Statement()
+	stmt.Evict(tasks["p1"], "preempted")
+	stmt.Discard()
+
+	stmt = ssn.Statement()
+	stmt.Pipeline(tasks["p3"], "n1")
+	stmt.Discard()
+
+	// Job pg2 is ready, but failed to bind.
+	if err := ssn.Allocate(tasks["p4"], "n1", false); err == nil {
+		t.Errorf("expected error of allocating p4")
+	}
+
+	expected := []string{
+		"allocated p1",
+		"Allocate pg1/p1 on n1",
+		"allocated p2",
+		"Allocate pg1/p2 on n1",
+		"JobReady pg1/p2 on n1",
+		"Dispatch pg1/p1 on n1",
+		"Dispatch pg1/p2 on n1",
+		"deallocated p1",
+		"Evict pg1/p1 on n1: preempted",
+		"allocated p1",
+		"Unevict pg1/p1 on n1: preempted",
+		"allocated p3",
+		"Pipeline pg2/p3 on n1",
+		"deallocated p3",
+		"Unpipeline pg2/p3 on n1",
+		"allocated p4",
+		"Allocate pg2/p4 on n1",
+		"JobReady pg2/p4 on n1",
+		"BindFailed pg2/p4 on n1: failed to bind volumes of p4",
+	}
+	// Allocated tasks of a ready job are dispatched in random order.
+	if len(erp.events) > 6 {
+		sort.Strings(erp.events[5:7])
+	}
+	if !reflect.DeepEqual(erp.events, expected) {
+		t.Errorf("expected events:\n%v\ngot:\n%v", expected, erp.events)
+	}
+}
@@ -47,10 +47,16 @@ func OpenSession(cache cache.Cache, tiers []conf.Tier) *Session {
 		metrics.UpdatePluginDuration(plugin.Name(), metrics.OnSessionOpen, metrics.Duration(onSessionOpenStart))
 	}
 
+	// The jobs rejected by JobValidFns, e.g. gang's NotEnoughTasks and
+	// InfeasibleResources, are not scheduled in this session.
+	filterInvalidJobs(ssn)
+
 	return ssn
 }
 
 func CloseSession(ssn *Session) {
+	ssn.Trace.recordJobs(ssn)
+
 	for _, plugin := range ssn.plugins {
 		onSessionCloseStart := time.Now()
 		plugin.OnSessionClose(ssn)
@@ -59,3 +65,55 @@ func CloseSession(ssn *Session) {
 
 	closeSession(ssn)
 }
+
+// OpenSessionWithConf opens a session of cache with the tiers of scheduler
+// configuration, and sets the arguments of actions and whether preemption is
+// enabled; it's shared by scheduler, replay and simulator, so their sessions
+// are the same.
+func OpenSessionWithConf(cache cache.Cache, tiers []conf.Tier,
+	actionOptions map[string]conf.ActionOption, enablePreemption bool) *Session {
+	ssn := OpenSession(cache, tiers)
+	ssn.EnablePreemption = enablePreemption
+	ssn.ActionArguments = map[string]Arguments{}
+	for name, option := range actionOptions {
+		ssn.ActionArguments[name] = option.Arguments
+	}
+
+	return ssn
+}
+
+// ActionRuns are the start time of the last runs of actions, by action name.
+type ActionRuns map[string]time.Time
+
+// Due returns whether the action should run in the scheduling cycle started
+// at now, and records the run if so; all actions are due on nil ActionRuns.
+func (r ActionRuns) Due(name string, interval time.Duration, now time.Time) bool {
+	if r == nil {
+		return true
+	}
+
+	if last, found := r[name]; found && now.Sub(last) < interval {
+		return false
+	}
+
+	r[name] = now
+	return true
+}
+
+// ExecuteActions executes actions in session of the scheduling cycle started
+// at now; the actions whose interval is not elapsed since their last runs are
+// skipped, and the intervals are ignored if runs is nil.
+func ExecuteActions(ssn *Session, actions []Action, actionOptions map[string]conf.ActionOption,
+	runs ActionRuns, now time.Time) {
+	for _, action := range actions {
+		if !runs.Due(action.Name(), actionOptions[action.Name()].Interval, now) {
+			glog.V(4).Infof("Skip action <%s>, its interval is not elapsed", action.Name())
+			metrics.RegisterActionSkipped(action.Name())
+			continue
+		}
+
+		actionStartTime := time.Now()
+		action.Execute(ssn)
+		metrics.UpdateActionDuration(action.Name(), metrics.Duration(actionStartTime))
+	}
+}
@@ -38,3 +38,13 @@ type Plugin interface {
 	OnSessionOpen(ssn *Session)
 	OnSessionClose(ssn *Session)
 }
+
+// ConcurrentPlugin is implemented by plugins whose predicate and node order
+// functions are safe to be called concurrently for different nodes.
+type ConcurrentPlugin interface {
+	Plugin
+
+	// ConcurrencySafe returns whether the predicate and node order functions
+	// of plugin can be called concurrently.
+	ConcurrencySafe() bool
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"plugin"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// PluginBuilderSymbol is the symbol of plugin builder exposed by custom plugin,
+	// whose type must be func(map[string]string) framework.Plugin.
+	PluginBuilderSymbol = "New"
+	// PluginArgumentsSymbol is the optional symbol of argument schema exposed by
+	// custom plugin, whose type must be framework.ArgumentSchema.
+	PluginArgumentsSymbol = "Arguments"
+	// PluginNameSymbol is the optional symbol of the name of custom plugin,
+	// whose type must be string; the name of .so file without extension is
+	// used if it's not exposed. The name must be the same as Name() of plugin.
+	PluginNameSymbol = "PluginName"
+)
+
+// LoadCustomPlugins loads the Go plugins, i.e. the .so files, in dir, and
+// registers their builders by the name of the plugin; it fails if the name
+// clashes with a registered plugin. The plugins are not built until they are
+// used in session.
+func LoadCustomPlugins(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read plugins dir %s: %v", dir, err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".so") {
+			continue
+		}
+
+		path := filepath.Join(dir, file.Name())
+		name, err := loadCustomPlugin(path)
+		if err != nil {
+			return err
+		}
+		glog.V(3).Infof("Loaded custom plugin <%s> from %s", name, path)
+	}
+
+	return nil
+}
+
+func loadCustomPlugin(path string) (string, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open plugin %s: %v", path, err)
+	}
+
+	symbol, err := p.Lookup(PluginBuilderSymbol)
+	if err != nil {
+		return "", fmt.Errorf("failed to find symbol %s in plugin %s: %v", PluginBuilderSymbol, path, err)
+	}
+
+	builder, ok := symbol.(func(map[string]string) Plugin)
+	if !ok {
+		return "", fmt.Errorf("symbol %s in plugin %s is %T, not func(map[string]string) framework.Plugin",
+			PluginBuilderSymbol, path, symbol)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), ".so")
+	if symbol, err := p.Lookup(PluginNameSymbol); err == nil {
+		n, ok := symbol.(*string)
+		if !ok {
+			return "", fmt.Errorf("symbol %s in plugin %s is %T, not string",
+				PluginNameSymbol, path, symbol)
+		}
+		name = *n
+	}
+	if len(name) == 0 {
+		return "", fmt.Errorf("plugin %s has empty name", path)
+	}
+	if _, found := GetPluginBuilder(name); found {
+		return "", fmt.Errorf("plugin <%s> in %s clashes with a registered plugin", name, path)
+	}
+
+	RegisterPluginBuilder(name, builder)
+
+	if symbol, err := p.Lookup(PluginArgumentsSymbol); err == nil {
+		schema, ok := symbol.(*ArgumentSchema)
+		if !ok {
+			return "", fmt.Errorf("symbol %s in plugin %s is %T, not framework.ArgumentSchema",
+				PluginArgumentsSymbol, path, symbol)
+		}
+		RegisterPluginArguments(name, *schema)
+	}
+
+	return name, nil
+}
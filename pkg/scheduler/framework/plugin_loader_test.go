@@ -0,0 +1,53 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadCustomPlugins(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kube-batch-plugins")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Files other than .so are ignored.
+	if err := ioutil.WriteFile(filepath.Join(dir, "README"), []byte("plugins"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := LoadCustomPlugins(dir); err != nil {
+		t.Errorf("expected no error without .so files, got %v", err)
+	}
+
+	if err := LoadCustomPlugins(filepath.Join(dir, "not-found")); err == nil {
+		t.Errorf("expected error for missing plugins dir")
+	}
+
+	bad := filepath.Join(dir, "bad.so")
+	if err := ioutil.WriteFile(bad, []byte("not a plugin"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := LoadCustomPlugins(dir); err == nil || !strings.Contains(err.Error(), bad) {
+		t.Errorf("expected error of %s, got %v", bad, err)
+	}
+}
@@ -32,11 +32,21 @@ func RegisterPluginBuilder(name string, pc func(map[string]string) Plugin) {
 	pluginBuilders[name] = pc
 }
 
+// UnregisterPluginBuilder unregisters the builder and argument schema of a plugin.
+func UnregisterPluginBuilder(name string) {
+	pluginMutex.Lock()
+	defer pluginMutex.Unlock()
+
+	delete(pluginBuilders, name)
+	delete(argumentSchemas, name)
+}
+
 func CleanupPluginBuilders() {
 	pluginMutex.Lock()
 	defer pluginMutex.Unlock()
 
 	pluginBuilders = map[string]PluginBuilder{}
+	argumentSchemas = map[string]ArgumentSchema{}
 }
 
 func GetPluginBuilder(name string) (PluginBuilder, bool) {
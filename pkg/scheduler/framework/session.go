@@ -47,48 +47,90 @@ type Session struct {
 	//TopDogReadyJobs map[api.JobID]*api.JobInfo
 	//Others          []*api.TaskInfo
 	EnablePreemption bool
-
-	plugins        map[string]Plugin
-	eventHandlers  []*EventHandler
-	jobOrderFns    map[string]api.CompareFn
-	queueOrderFns  map[string]api.CompareFn
-	taskOrderFns   map[string]api.CompareFn
-	predicateFns   map[string]api.PredicateFn
-	nodeOrderFns   map[string]api.NodeOrderFn
-	preemptableFns map[string]api.EvictableFn
-	reclaimableFns map[string]api.EvictableFn
-	overusedFns    map[string]api.ValidateFn
-	jobReadyFns    map[string]api.JobReadyFn
-	jobValidFns    map[string]api.ValidateExFn
+	// ActionArguments are the arguments of actions in scheduler configuration, by action name.
+	ActionArguments map[string]Arguments
+	// Trace is the scheduling decisions made in session.
+	Trace *Trace
+
+	// allJobs are all the jobs of snapshot, including the ones not scheduled
+	// in session, e.g. failed or invalid jobs; they're counted in the status
+	// of queues.
+	allJobs map[api.JobID]*api.JobInfo
+
+	plugins             map[string]Plugin
+	eventHandlers       []*EventHandler
+	jobOrderFns         map[string]api.CompareFn
+	queueOrderFns       map[string]api.CompareFn
+	taskOrderFns        map[string]api.CompareFn
+	predicateFns        map[string]api.PredicateFn
+	nodeOrderFns        map[string]api.NodeOrderFn
+	batchNodeOrderFns   map[string]api.BatchNodeOrderFn
+	preemptableFns      map[string]api.EvictableFn
+	reclaimableFns      map[string]api.EvictableFn
+	overusedFns         map[string]api.ValidateFn
+	jobReadyFns         map[string]api.JobReadyFn
+	jobValidFns         map[string]api.ValidateExFn
 	backFillEligibleFns map[string]api.BackFillEligibleFn
+	jobEnqueueableFns   map[string]api.ValidateFn
 }
 
 func openSession(cache cache.Cache) *Session {
+	uid := uuid.NewUUID()
 	ssn := &Session{
-		UID:   uuid.NewUUID(),
+		UID:   uid,
 		cache: cache,
+		Trace: newTrace(uid),
 
 		Jobs:   map[api.JobID]*api.JobInfo{},
 		Nodes:  map[string]*api.NodeInfo{},
 		Queues: map[api.QueueID]*api.QueueInfo{},
 
-		plugins:        map[string]Plugin{},
-		jobOrderFns:    map[string]api.CompareFn{},
-		queueOrderFns:  map[string]api.CompareFn{},
-		taskOrderFns:   map[string]api.CompareFn{},
-		predicateFns:   map[string]api.PredicateFn{},
-		nodeOrderFns:   map[string]api.NodeOrderFn{},
-		preemptableFns: map[string]api.EvictableFn{},
-		reclaimableFns: map[string]api.EvictableFn{},
-		overusedFns:    map[string]api.ValidateFn{},
-		jobReadyFns:    map[string]api.JobReadyFn{},
-		jobValidFns:    map[string]api.ValidateExFn{},
+		plugins:             map[string]Plugin{},
+		jobOrderFns:         map[string]api.CompareFn{},
+		queueOrderFns:       map[string]api.CompareFn{},
+		taskOrderFns:        map[string]api.CompareFn{},
+		predicateFns:        map[string]api.PredicateFn{},
+		nodeOrderFns:        map[string]api.NodeOrderFn{},
+		batchNodeOrderFns:   map[string]api.BatchNodeOrderFn{},
+		preemptableFns:      map[string]api.EvictableFn{},
+		reclaimableFns:      map[string]api.EvictableFn{},
+		overusedFns:         map[string]api.ValidateFn{},
+		jobReadyFns:         map[string]api.JobReadyFn{},
+		jobValidFns:         map[string]api.ValidateExFn{},
 		backFillEligibleFns: map[string]api.BackFillEligibleFn{},
+		jobEnqueueableFns:   map[string]api.ValidateFn{},
 	}
 
 	snapshot := cache.Snapshot()
 
 	ssn.Jobs = snapshot.Jobs
+	ssn.allJobs = make(map[api.JobID]*api.JobInfo, len(snapshot.Jobs))
+	for _, job := range ssn.Jobs {
+		ssn.allJobs[job.UID] = job
+	}
+	for _, job := range ssn.Jobs {
+		// The failed PodGroup will not be scheduled anymore.
+		if job.PodGroup != nil && job.PodGroup.Status.Phase == v1alpha1.PodGroupFailed {
+			glog.V(4).Infof("Skip failed job <%s/%s>.", job.Namespace, job.Name)
+			delete(ssn.Jobs, job.UID)
+		}
+	}
+	ssn.Nodes = snapshot.Nodes
+	ssn.Queues = snapshot.Queues
+
+	//ssn.TopDogReadyJobs = map[api.JobID]*api.JobInfo{}
+
+	glog.V(3).Infof("Open Session %v with <%d> Job and <%d> Queues",
+		ssn.UID, len(ssn.Jobs), len(ssn.Queues))
+
+	return ssn
+}
+
+// filterInvalidJobs removes the jobs which are not valid from the session, e.g. the
+// jobs without enough tasks for gang-scheduling, and updates their PodGroups with
+// the reason. It runs after the JobValidFns of plugins are registered, so plugins
+// have counted the removed jobs when they were opened.
+func filterInvalidJobs(ssn *Session) {
 	for _, job := range ssn.Jobs {
 		if vjr := ssn.JobValid(job); vjr != nil {
 			if !vjr.Pass {
@@ -104,21 +146,18 @@ func openSession(cache cache.Cache) *Session {
 				if err := ssn.UpdateJobCondition(job, jc); err != nil {
 					glog.Errorf("Failed to update job condition: %v", err)
 				}
+				ssn.Trace.recordInvalid(job, vjr)
+				// The job is not in session any more, update its status here
+				// instead of closeSession.
+				if _, err := ssn.cache.UpdateJobStatus(job); err != nil {
+					glog.Errorf("Failed to update job <%s/%s>: %v",
+						job.Namespace, job.Name, err)
+				}
 			}
 
 			delete(ssn.Jobs, job.UID)
 		}
 	}
-
-	ssn.Nodes = snapshot.Nodes
-	ssn.Queues = snapshot.Queues
-
-	//ssn.TopDogReadyJobs = map[api.JobID]*api.JobInfo{}
-
-	glog.V(3).Infof("Open Session %v with <%d> Job and <%d> Queues",
-		ssn.UID, len(ssn.Jobs), len(ssn.Queues))
-
-	return ssn
 }
 
 func closeSession(ssn *Session) {
@@ -144,7 +183,10 @@ func closeSession(ssn *Session) {
 		}
 	}
 
+	updateQueueStatus(ssn)
+
 	ssn.Jobs = nil
+	ssn.allJobs = nil
 	ssn.Nodes = nil
 	ssn.Backlog = nil
 	ssn.plugins = nil
@@ -155,11 +197,43 @@ func closeSession(ssn *Session) {
 	glog.V(3).Infof("Close Session %v", ssn.UID)
 }
 
+// updateQueueStatus updates the number of jobs of queues by phase, which are
+// counted from all the jobs of session, including the ones not scheduled.
+func updateQueueStatus(ssn *Session) {
+	phases := map[api.QueueID]map[v1alpha1.PodGroupPhase]int32{}
+	for _, job := range ssn.allJobs {
+		if job.PodGroup == nil {
+			continue
+		}
+		if _, found := phases[job.Queue]; !found {
+			phases[job.Queue] = map[v1alpha1.PodGroupPhase]int32{}
+		}
+		phases[job.Queue][job.PodGroup.Status.Phase]++
+	}
+
+	for _, queue := range ssn.Queues {
+		queue.Queue.Status.Pending = phases[queue.UID][v1alpha1.PodGroupPending]
+		queue.Queue.Status.Running = phases[queue.UID][v1alpha1.PodGroupRunning]
+		queue.Queue.Status.Unknown = phases[queue.UID][v1alpha1.PodGroupUnknown]
+		queue.Queue.Status.InQueue = phases[queue.UID][v1alpha1.PodGroupInQueue]
+		queue.Queue.Status.State = queue.State
+
+		if err := ssn.cache.UpdateQueueStatus(queue); err != nil {
+			glog.Errorf("Failed to update queue <%s>: %v", queue.Name, err)
+		}
+	}
+}
+
 func jobStatus(ssn *Session, jobInfo *api.JobInfo) v1alpha1.PodGroupStatus {
 	status := jobInfo.PodGroup.Status
 
 	glog.Infof("pod group %s with status %v", jobInfo.Name, status)
 
+	// Failed is the final phase of PodGroup.
+	if status.Phase == v1alpha1.PodGroupFailed {
+		return status
+	}
+
 	unschedulable := false
 	for _, c := range status.Conditions {
 		if c.Type == v1alpha1.PodGroupUnschedulableType &&
@@ -177,6 +251,9 @@ func jobStatus(ssn *Session, jobInfo *api.JobInfo) v1alpha1.PodGroupStatus {
 		// TODO Terry: Check GetReadiness() definition
 		if jobInfo.GetReadiness() == api.Ready {
 			status.Phase = v1alpha1.PodGroupRunning
+		} else if status.Phase == v1alpha1.PodGroupInQueue && !unschedulable {
+			// Keep InQueue until it's unschedulable, e.g. not enough resources anymore.
+			status.Phase = v1alpha1.PodGroupInQueue
 		} else {
 			status.Phase = v1alpha1.PodGroupPending
 		}
@@ -223,13 +300,11 @@ func (ssn *Session) Pipeline(task *api.TaskInfo, hostname string) error {
 			hostname, ssn.UID)
 	}
 
-	for _, eh := range ssn.eventHandlers {
-		if eh.AllocateFunc != nil {
-			eh.AllocateFunc(&Event{
-				Task: task,
-			})
-		}
-	}
+	ssn.fireEvent(&Event{
+		Type:     PipelineEvent,
+		Task:     task,
+		NodeName: hostname,
+	})
 
 	return nil
 }
@@ -240,8 +315,11 @@ func (ssn *Session) Allocate(task *api.TaskInfo, hostname string, usingBackfillT
 	}
 
 	// Only update status in session
+	var wasReady bool
 	job, found := ssn.Jobs[task.Job]
 	if found {
+		wasReady = ssn.JobReady(job)
+
 		newStatus := api.Allocated
 		// TODO Terry: Can we use Pipelined?
 		if usingBackfillTaskRes {
@@ -275,15 +353,23 @@ func (ssn *Session) Allocate(task *api.TaskInfo, hostname string, usingBackfillT
 	}
 
 	// Callbacks
-	for _, eh := range ssn.eventHandlers {
-		if eh.AllocateFunc != nil {
-			eh.AllocateFunc(&Event{
-				Task: task,
+	ssn.fireEvent(&Event{
+		Type:     AllocateEvent,
+		Task:     task,
+		Job:      job,
+		NodeName: hostname,
+	})
+
+	if ssn.JobReady(job) {
+		if !wasReady {
+			ssn.fireEvent(&Event{
+				Type:     JobReadyEvent,
+				Task:     task,
+				Job:      job,
+				NodeName: hostname,
 			})
 		}
-	}
 
-	if ssn.JobReady(job) {
 		for _, task := range job.TaskStatusIndex[api.Allocated] {
 			if err := ssn.dispatch(task); err != nil {
 				glog.Errorf("Failed to dispatch task <%v/%v>: %v",
@@ -298,10 +384,12 @@ func (ssn *Session) Allocate(task *api.TaskInfo, hostname string, usingBackfillT
 
 func (ssn *Session) dispatch(task *api.TaskInfo) error {
 	if err := ssn.cache.BindVolumes(task); err != nil {
+		ssn.bindFailed(task, err)
 		return err
 	}
 
 	if err := ssn.cache.Bind(task, task.NodeName); err != nil {
+		ssn.bindFailed(task, err)
 		return err
 	}
 
@@ -316,10 +404,26 @@ func (ssn *Session) dispatch(task *api.TaskInfo) error {
 			task.Job, ssn.UID)
 	}
 
+	ssn.fireEvent(&Event{
+		Type:     DispatchEvent,
+		Task:     task,
+		NodeName: task.NodeName,
+	})
+
 	metrics.UpdateTaskScheduleDuration(metrics.Duration(task.Pod.CreationTimestamp.Time))
 	return nil
 }
 
+func (ssn *Session) bindFailed(task *api.TaskInfo, err error) {
+	ssn.fireEvent(&Event{
+		Type:     BindFailedEvent,
+		Task:     task,
+		NodeName: task.NodeName,
+		Reason:   err.Error(),
+		Err:      err,
+	})
+}
+
 func (ssn *Session) Evict(reclaimee *api.TaskInfo, reason string) error {
 	if err := ssn.cache.Evict(reclaimee, reason); err != nil {
 		return err
@@ -345,13 +449,13 @@ func (ssn *Session) Evict(reclaimee *api.TaskInfo, reason string) error {
 		}
 	}
 
-	for _, eh := range ssn.eventHandlers {
-		if eh.DeallocateFunc != nil {
-			eh.DeallocateFunc(&Event{
-				Task: reclaimee,
-			})
-		}
-	}
+	ssn.fireEvent(&Event{
+		Type:     EvictEvent,
+		Task:     reclaimee,
+		Job:      job,
+		NodeName: reclaimee.NodeName,
+		Reason:   reason,
+	})
 
 	return nil
 }
@@ -385,6 +489,30 @@ func (ssn *Session) AddEventHandler(eh *EventHandler) {
 	ssn.eventHandlers = append(ssn.eventHandlers, eh)
 }
 
+// fireEvent calls the handlers of event in the order they're added.
+func (ssn *Session) fireEvent(event *Event) {
+	if event.Job == nil && event.Task != nil {
+		event.Job = ssn.Jobs[event.Task.Job]
+	}
+
+	for _, eh := range ssn.eventHandlers {
+		switch event.Type {
+		case PipelineEvent, AllocateEvent, UnevictEvent:
+			if eh.AllocateFunc != nil {
+				eh.AllocateFunc(event)
+			}
+		case EvictEvent, UnpipelineEvent, UnallocateEvent:
+			if eh.DeallocateFunc != nil {
+				eh.DeallocateFunc(event)
+			}
+		}
+
+		if fn, found := eh.EventFuncs[event.Type]; found && fn != nil {
+			fn(event)
+		}
+	}
+}
+
 func (ssn Session) String() string {
 	msg := fmt.Sprintf("Session %v: \n", ssn.UID)
 
@@ -17,9 +17,19 @@ limitations under the License.
 package framework
 
 import (
+	"context"
+
+	"github.com/golang/glog"
+
+	"k8s.io/client-go/util/workqueue"
+
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
 )
 
+// MaxNodeScore is the maximum score of node given by a plugin after the
+// scores of the plugin are normalized.
+const MaxNodeScore = 100
+
 func (ssn *Session) AddJobOrderFn(name string, cf api.CompareFn) {
 	ssn.jobOrderFns[name] = cf
 }
@@ -52,6 +62,10 @@ func (ssn *Session) AddNodeOrderFn(name string, pf api.NodeOrderFn) {
 	ssn.nodeOrderFns[name] = pf
 }
 
+func (ssn *Session) AddBatchNodeOrderFn(name string, pf api.BatchNodeOrderFn) {
+	ssn.batchNodeOrderFns[name] = pf
+}
+
 func (ssn *Session) AddOverusedFn(name string, fn api.ValidateFn) {
 	ssn.overusedFns[name] = fn
 }
@@ -60,6 +74,10 @@ func (ssn *Session) AddJobValidFn(name string, fn api.ValidateExFn) {
 	ssn.jobValidFns[name] = fn
 }
 
+func (ssn *Session) AddJobEnqueueableFn(name string, fn api.ValidateFn) {
+	ssn.jobEnqueueableFns[name] = fn
+}
+
 func (ssn *Session) AddBackFillEligibleFn(name string, fn api.BackFillEligibleFn) {
 	ssn.backFillEligibleFns[name] = fn
 }
@@ -148,6 +166,12 @@ func (ssn *Session) Preemptable(preemptor *api.TaskInfo, preemptees []*api.TaskI
 }
 
 func (ssn *Session) Overused(queue *api.QueueInfo) bool {
+	overused := ssn.overused(queue)
+	ssn.Trace.recordOverused(queue, overused)
+	return overused
+}
+
+func (ssn *Session) overused(queue *api.QueueInfo) bool {
 	for _, tier := range ssn.Tiers {
 		for _, plugin := range tier.Plugins {
 			of, found := ssn.overusedFns[plugin.Name]
@@ -163,6 +187,24 @@ func (ssn *Session) Overused(queue *api.QueueInfo) bool {
 	return false
 }
 
+// JobEnqueueable returns whether the job can be admitted into InQueue; the job is
+// enqueueable only if all plugins permit it.
+func (ssn *Session) JobEnqueueable(obj interface{}) bool {
+	for _, tier := range ssn.Tiers {
+		for _, plugin := range tier.Plugins {
+			ef, found := ssn.jobEnqueueableFns[plugin.Name]
+			if !found {
+				continue
+			}
+			if !ef(obj) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
 // TODO Terry: Move JobReady into JobInfo?
 func (ssn *Session) JobReady(obj interface{}) bool {
 	status := api.Ready
@@ -329,6 +371,52 @@ func (ssn *Session) TaskOrderFn(l, r interface{}) bool {
 }
 
 func (ssn *Session) PredicateFn(task *api.TaskInfo, node *api.NodeInfo) error {
+	err := ssn.predicate(task, node)
+	ssn.Trace.recordPredicate(ssn.Jobs[task.Job], task, node.Name, err)
+	return err
+}
+
+// PredicateNodes returns the first numNodesToFind nodes which task can be
+// placed on. Nodes are evaluated by workers in batches, and the batch is
+// always evaluated as a whole, so the result is the same as evaluating
+// nodes one by one; the failures are recorded in trace once at the end.
+func (ssn *Session) PredicateNodes(task *api.TaskInfo, nodes []*api.NodeInfo,
+	numNodesToFind, workers int) []*api.NodeInfo {
+	errs := make([]error, len(nodes))
+	result := []*api.NodeInfo{}
+
+	start := 0
+	for start < len(nodes) && len(result) < numNodesToFind {
+		size := numNodesToFind - len(result)
+		if size < workers {
+			size = workers
+		}
+		batch := nodes[start:]
+		if size < len(batch) {
+			batch = batch[:size]
+		}
+
+		workqueue.ParallelizeUntil(context.TODO(), workers, len(batch), func(i int) {
+			errs[start+i] = ssn.predicate(task, batch[i])
+		})
+
+		for i, node := range batch {
+			if err := errs[start+i]; err != nil {
+				glog.V(3).Infof("Predicates failed for task <%s/%s> on node <%s>: %v",
+					task.Namespace, task.Name, node.Name, err)
+			} else if len(result) < numNodesToFind {
+				result = append(result, node)
+			}
+		}
+		start += len(batch)
+	}
+
+	ssn.Trace.recordPredicates(ssn.Jobs[task.Job], task, nodes[:start], errs[:start])
+
+	return result
+}
+
+func (ssn *Session) predicate(task *api.TaskInfo, node *api.NodeInfo) error {
 	for _, tier := range ssn.Tiers {
 		for _, plugin := range tier.Plugins {
 			if plugin.PredicateDisabled {
@@ -347,6 +435,8 @@ func (ssn *Session) PredicateFn(task *api.TaskInfo, node *api.NodeInfo) error {
 	return nil
 }
 
+// NodeOrderFn returns the sum of the scores of node given by plugins, which
+// are not normalized; PrioritizeNodes should be used to compare nodes.
 func (ssn *Session) NodeOrderFn(task *api.TaskInfo, node *api.NodeInfo) (int, error) {
 	priorityScore := 0
 	for _, tier := range ssn.Tiers {
@@ -368,3 +458,159 @@ func (ssn *Session) NodeOrderFn(task *api.TaskInfo, node *api.NodeInfo) (int, er
 	}
 	return priorityScore, nil
 }
+
+// ConcurrencySafe returns whether PredicateFn and NodeOrderFn can be called
+// concurrently, i.e. all plugins enabled for them are ConcurrentPlugin and safe.
+func (ssn *Session) ConcurrencySafe() bool {
+	for _, tier := range ssn.Tiers {
+		for _, plugin := range tier.Plugins {
+			_, hasPredicate := ssn.predicateFns[plugin.Name]
+			_, hasNodeOrder := ssn.nodeOrderFns[plugin.Name]
+			if (!hasPredicate || plugin.PredicateDisabled) &&
+				(!hasNodeOrder || plugin.NodeOrderDisabled) {
+				continue
+			}
+
+			cp, ok := ssn.plugins[plugin.Name].(ConcurrentPlugin)
+			if !ok || !cp.ConcurrencySafe() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// NodeOrderMapFn returns the scores of node given by the NodeOrderFn of each
+// plugin, by plugin name.
+func (ssn *Session) NodeOrderMapFn(task *api.TaskInfo, node *api.NodeInfo) (map[string]int, error) {
+	scores := map[string]int{}
+	for _, tier := range ssn.Tiers {
+		for _, plugin := range tier.Plugins {
+			if plugin.NodeOrderDisabled {
+				continue
+			}
+			pfn, found := ssn.nodeOrderFns[plugin.Name]
+			if !found {
+				continue
+			}
+			score, err := pfn(task, node)
+			if err != nil {
+				return nil, err
+			}
+			scores[plugin.Name] = score
+		}
+	}
+	return scores, nil
+}
+
+// NodeOrderReduceFn adds the scores given by the BatchNodeOrderFn of each plugin
+// to the scores of nodes by NodeOrderMapFn, normalizes the scores of each plugin
+// onto [0, MaxNodeScore] across nodes, and returns the sum of the normalized
+// scores multiplied by the nodeOrderWeight of plugins, by node name.
+func (ssn *Session) NodeOrderReduceFn(task *api.TaskInfo, nodes []*api.NodeInfo,
+	nodeScores map[string]map[string]int) map[string]int {
+	result := map[string]int{}
+	for _, node := range nodes {
+		result[node.Name] = 0
+	}
+
+	for _, tier := range ssn.Tiers {
+		for _, plugin := range tier.Plugins {
+			if plugin.NodeOrderDisabled {
+				continue
+			}
+			_, hasMap := ssn.nodeOrderFns[plugin.Name]
+			bfn, hasBatch := ssn.batchNodeOrderFns[plugin.Name]
+			if !hasMap && !hasBatch {
+				continue
+			}
+
+			scores := make(map[string]int, len(nodes))
+			for _, node := range nodes {
+				scores[node.Name] = nodeScores[node.Name][plugin.Name]
+			}
+			if hasBatch {
+				batchScores, err := bfn(task, nodes)
+				if err != nil {
+					glog.Errorf("Failed to get batch node scores of task <%s/%s> by plugin <%s>: %v",
+						task.Namespace, task.Name, plugin.Name, err)
+				}
+				for name, score := range batchScores {
+					if _, found := scores[name]; found {
+						scores[name] += score
+					}
+				}
+			}
+
+			weight := plugin.NodeOrderWeight
+			if weight == 0 {
+				weight = 1
+			}
+			for name, score := range normalizeScores(scores) {
+				result[name] += score * weight
+			}
+		}
+	}
+
+	return result
+}
+
+// normalizeScores maps scores onto [0, MaxNodeScore] linearly, from the
+// lowest to the highest; all scores are 0 if they're the same.
+func normalizeScores(scores map[string]int) map[string]int {
+	first := true
+	var min, max int
+	for _, score := range scores {
+		if first || score < min {
+			min = score
+		}
+		if first || score > max {
+			max = score
+		}
+		first = false
+	}
+
+	normalized := make(map[string]int, len(scores))
+	for name, score := range scores {
+		if max == min {
+			normalized[name] = 0
+		} else {
+			normalized[name] = (score - min) * MaxNodeScore / (max - min)
+		}
+	}
+	return normalized
+}
+
+// PrioritizeNodes returns nodes by their final scores for task, which are
+// calculated by NodeOrderMapFn on nodes with the given number of workers,
+// then NodeOrderReduceFn; nodes of the same score are in the given order,
+// and nodes failed by NodeOrderMapFn are skipped.
+func (ssn *Session) PrioritizeNodes(task *api.TaskInfo, nodes []*api.NodeInfo, workers int) map[int][]*api.NodeInfo {
+	scores := make([]map[string]int, len(nodes))
+	errs := make([]error, len(nodes))
+
+	workqueue.ParallelizeUntil(context.TODO(), workers, len(nodes), func(i int) {
+		scores[i], errs[i] = ssn.NodeOrderMapFn(task, nodes[i])
+	})
+
+	scoredNodes := make([]*api.NodeInfo, 0, len(nodes))
+	nodeScores := make(map[string]map[string]int, len(nodes))
+	for i, node := range nodes {
+		if errs[i] != nil {
+			glog.V(3).Infof("Error in Calculating Priority for the node:%v", errs[i])
+			continue
+		}
+		scoredNodes = append(scoredNodes, node)
+		nodeScores[node.Name] = scores[i]
+	}
+
+	finalScores := ssn.NodeOrderReduceFn(task, scoredNodes, nodeScores)
+	ssn.Trace.recordScores(ssn.Jobs[task.Job], finalScores)
+
+	result := map[int][]*api.NodeInfo{}
+	for _, node := range scoredNodes {
+		score := finalScores[node.Name]
+		result[score] = append(result[score], node)
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+)
+
+func TestPrioritizeNodes(t *testing.T) {
+	nodes := []*api.NodeInfo{}
+	index := map[string]int{}
+	for i := 0; i < 3; i++ {
+		name := fmt.Sprintf("n%d", i)
+		nodes = append(nodes, &api.NodeInfo{Name: name})
+		index[name] = i
+	}
+
+	// Plugin "large" prefers nodes of larger index in hundreds, while
+	// plugin "small" prefers nodes of smaller index in 0-10 by batch.
+	large := func(task *api.TaskInfo, node *api.NodeInfo) (int, error) {
+		if node.Name == "failed" {
+			return 0, fmt.Errorf("failed to score node")
+		}
+		return index[node.Name] * 100, nil
+	}
+	small := func(task *api.TaskInfo, nodes []*api.NodeInfo) (map[string]int, error) {
+		scores := map[string]int{}
+		for _, node := range nodes {
+			scores[node.Name] = 10 - index[node.Name]
+		}
+		return scores, nil
+	}
+
+	tests := []struct {
+		name     string
+		plugins  []conf.PluginOption
+		nodes    []*api.NodeInfo
+		expected map[int][]string
+	}{
+		{
+			name:    "scores are normalized",
+			plugins: []conf.PluginOption{{Name: "large"}, {Name: "small"}},
+			nodes:   nodes,
+			expected: map[int][]string{
+				100: {"n0", "n1", "n2"},
+			},
+		},
+		{
+			name:    "scores are weighted",
+			plugins: []conf.PluginOption{{Name: "large"}, {Name: "small", NodeOrderWeight: 3}},
+			nodes:   nodes,
+			expected: map[int][]string{
+				300: {"n0"},
+				200: {"n1"},
+				100: {"n2"},
+			},
+		},
+		{
+			name:    "disabled plugin is skipped",
+			plugins: []conf.PluginOption{{Name: "large"}, {Name: "small", NodeOrderDisabled: true}},
+			nodes:   nodes,
+			expected: map[int][]string{
+				0:   {"n0"},
+				50:  {"n1"},
+				100: {"n2"},
+			},
+		},
+		{
+			name:    "failed node is skipped",
+			plugins: []conf.PluginOption{{Name: "large"}},
+			nodes:   append([]*api.NodeInfo{{Name: "failed"}}, nodes[:2]...),
+			expected: map[int][]string{
+				0:   {"n0"},
+				100: {"n1"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		ssn := &Session{
+			Tiers:             []conf.Tier{{Plugins: test.plugins}},
+			nodeOrderFns:      map[string]api.NodeOrderFn{"large": large},
+			batchNodeOrderFns: map[string]api.BatchNodeOrderFn{"small": small},
+		}
+
+		got := map[int][]string{}
+		for score, nodes := range ssn.PrioritizeNodes(&api.TaskInfo{}, test.nodes, 2) {
+			for _, node := range nodes {
+				got[score] = append(got[score], node.Name)
+			}
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("case <%s>: expected scores %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package framework
 
 import (
+	"fmt"
+
 	"github.com/golang/glog"
 
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
@@ -50,13 +52,12 @@ func (s *Statement) Evict(reclaimee *api.TaskInfo, reason string) error {
 		node.UpdateTask(reclaimee)
 	}
 
-	for _, eh := range s.ssn.eventHandlers {
-		if eh.DeallocateFunc != nil {
-			eh.DeallocateFunc(&Event{
-				Task: reclaimee,
-			})
-		}
-	}
+	s.ssn.fireEvent(&Event{
+		Type:     EvictEvent,
+		Task:     reclaimee,
+		NodeName: reclaimee.NodeName,
+		Reason:   reason,
+	})
 
 	s.operations = append(s.operations, operation{
 		name: "evict",
@@ -68,7 +69,7 @@ func (s *Statement) Evict(reclaimee *api.TaskInfo, reason string) error {
 
 func (s *Statement) evict(reclaimee *api.TaskInfo, reason string) error {
 	if err := s.ssn.cache.Evict(reclaimee, reason); err != nil {
-		if e := s.unevict(reclaimee, reason); err != nil {
+		if e := s.unevict(reclaimee, reason); e != nil {
 			glog.Errorf("Faled to unevict task <%v/%v>: %v.",
 				reclaimee.Namespace, reclaimee.Name, e)
 		}
@@ -96,13 +97,12 @@ func (s *Statement) unevict(reclaimee *api.TaskInfo, reason string) error {
 		node.AddTask(reclaimee)
 	}
 
-	for _, eh := range s.ssn.eventHandlers {
-		if eh.AllocateFunc != nil {
-			eh.AllocateFunc(&Event{
-				Task: reclaimee,
-			})
-		}
-	}
+	s.ssn.fireEvent(&Event{
+		Type:     UnevictEvent,
+		Task:     reclaimee,
+		NodeName: reclaimee.NodeName,
+		Reason:   reason,
+	})
 
 	return nil
 }
@@ -134,13 +134,11 @@ func (s *Statement) Pipeline(task *api.TaskInfo, hostname string) error {
 			hostname, s.ssn.UID)
 	}
 
-	for _, eh := range s.ssn.eventHandlers {
-		if eh.AllocateFunc != nil {
-			eh.AllocateFunc(&Event{
-				Task: task,
-			})
-		}
-	}
+	s.ssn.fireEvent(&Event{
+		Type:     PipelineEvent,
+		Task:     task,
+		NodeName: hostname,
+	})
 
 	s.operations = append(s.operations, operation{
 		name: "pipeline",
@@ -180,26 +178,182 @@ func (s *Statement) unpipeline(task *api.TaskInfo) error {
 			hostname, s.ssn.UID)
 	}
 
-	for _, eh := range s.ssn.eventHandlers {
-		if eh.DeallocateFunc != nil {
-			eh.DeallocateFunc(&Event{
-				Task: task,
-			})
+	s.ssn.fireEvent(&Event{
+		Type:     UnpipelineEvent,
+		Task:     task,
+		NodeName: hostname,
+	})
+
+	return nil
+}
+
+// Allocate allocates task onto node in session only; the task is bound to
+// the node when the statement is committed.
+func (s *Statement) Allocate(task *api.TaskInfo, hostname string, usingBackfillTaskRes bool) error {
+	if err := s.ssn.cache.AllocateVolumes(task, hostname); err != nil {
+		return err
+	}
+
+	// Only update status in session
+	var wasReady bool
+	job, found := s.ssn.Jobs[task.Job]
+	if found {
+		wasReady = s.ssn.JobReady(job)
+
+		newStatus := api.Allocated
+		if usingBackfillTaskRes {
+			newStatus = api.AllocatedOverBackfill
+		}
+		if err := job.UpdateTaskStatus(task, newStatus); err != nil {
+			glog.Errorf("Failed to update task <%v/%v> status to %v in Session <%v>: %v",
+				task.Namespace, task.Name, newStatus, s.ssn.UID, err)
+			s.ssn.cache.RevertVolumes(task)
+			return err
+		}
+	} else {
+		glog.Errorf("Failed to found Job <%s> in Session <%s> index when binding.",
+			task.Job, s.ssn.UID)
+		s.ssn.cache.RevertVolumes(task)
+		return fmt.Errorf("failed to find job %s", task.Job)
+	}
+
+	task.NodeName = hostname
+
+	if node, found := s.ssn.Nodes[hostname]; found {
+		if err := node.AddTask(task); err != nil {
+			glog.Errorf("Failed to add task <%v/%v> to node <%v> in Session <%v>: %v",
+				task.Namespace, task.Name, hostname, s.ssn.UID, err)
+			if e := job.UpdateTaskStatus(task, api.Pending); e != nil {
+				glog.Errorf("Failed to update task <%v/%v> status to %v in Session <%v>: %v",
+					task.Namespace, task.Name, api.Pending, s.ssn.UID, e)
+			}
+			task.NodeName = ""
+			s.ssn.cache.RevertVolumes(task)
+			return err
+		}
+		glog.V(3).Infof("After allocated Task <%v/%v> to Node <%v>: idle <%v>, used <%v>, releasing <%v>",
+			task.Namespace, task.Name, node.Name, node.Idle, node.Used, node.Releasing)
+	} else {
+		glog.Errorf("Failed to found Node <%s> in Session <%s> index when binding.",
+			hostname, s.ssn.UID)
+		if e := job.UpdateTaskStatus(task, api.Pending); e != nil {
+			glog.Errorf("Failed to update task <%v/%v> status to %v in Session <%v>: %v",
+				task.Namespace, task.Name, api.Pending, s.ssn.UID, e)
 		}
+		task.NodeName = ""
+		s.ssn.cache.RevertVolumes(task)
+		return fmt.Errorf("failed to find node %s", hostname)
 	}
 
+	// Callbacks
+	s.ssn.fireEvent(&Event{
+		Type:     AllocateEvent,
+		Task:     task,
+		Job:      job,
+		NodeName: hostname,
+	})
+
+	if !wasReady && s.ssn.JobReady(job) {
+		s.ssn.fireEvent(&Event{
+			Type:     JobReadyEvent,
+			Task:     task,
+			Job:      job,
+			NodeName: hostname,
+		})
+	}
+
+	s.operations = append(s.operations, operation{
+		name: "allocate",
+		args: []interface{}{task, hostname},
+	})
+
 	return nil
 }
 
+func (s *Statement) allocate(task *api.TaskInfo) error {
+	if err := s.ssn.dispatch(task); err != nil {
+		glog.Errorf("Failed to dispatch task <%v/%v>: %v",
+			task.Namespace, task.Name, err)
+		if e := s.unallocate(task); e != nil {
+			glog.Errorf("Failed to unallocate task <%v/%v>: %v.",
+				task.Namespace, task.Name, e)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// unallocate undoes everything Allocate did to the task: its volumes, status,
+// node and the allocation of plugins.
+func (s *Statement) unallocate(task *api.TaskInfo) error {
+	var err error
+
+	s.ssn.cache.RevertVolumes(task)
+
+	// Only update status in session
+	job, found := s.ssn.Jobs[task.Job]
+	if found {
+		if e := job.UpdateTaskStatus(task, api.Pending); e != nil {
+			glog.Errorf("Failed to update task <%v/%v> status to %v in Session <%v>: %v",
+				task.Namespace, task.Name, api.Pending, s.ssn.UID, e)
+			err = e
+		}
+	} else {
+		glog.Errorf("Failed to found Job <%s> in Session <%s> index when unallocating.",
+			task.Job, s.ssn.UID)
+		err = fmt.Errorf("failed to find job %s", task.Job)
+	}
+
+	hostname := task.NodeName
+
+	if node, found := s.ssn.Nodes[hostname]; found {
+		if e := node.RemoveTask(task); e != nil {
+			glog.Errorf("Failed to remove task <%v/%v> from node <%v> in Session <%v>: %v",
+				task.Namespace, task.Name, hostname, s.ssn.UID, e)
+			if err == nil {
+				err = e
+			}
+		}
+		glog.V(3).Infof("After unallocated Task <%v/%v> from Node <%v>: idle <%v>, used <%v>, releasing <%v>",
+			task.Namespace, task.Name, node.Name, node.Idle, node.Used, node.Releasing)
+	} else {
+		glog.Errorf("Failed to found Node <%s> in Session <%s> index when unallocating.",
+			hostname, s.ssn.UID)
+		if err == nil {
+			err = fmt.Errorf("failed to find node %s", hostname)
+		}
+	}
+
+	s.ssn.fireEvent(&Event{
+		Type:     UnallocateEvent,
+		Task:     task,
+		NodeName: hostname,
+	})
+
+	// The node of task is cleared after the plugins handled the event.
+	task.NodeName = ""
+
+	return err
+}
+
 func (s *Statement) Discard() {
 	glog.V(3).Info("Discarding operations ...")
 	for i := len(s.operations) - 1; i >= 0; i-- {
 		op := s.operations[i]
+		task := op.args[0].(*api.TaskInfo)
+		var err error
 		switch op.name {
 		case "evict":
-			s.unevict(op.args[0].(*api.TaskInfo), op.args[1].(string))
+			err = s.unevict(task, op.args[1].(string))
 		case "pipeline":
-			s.unpipeline(op.args[0].(*api.TaskInfo))
+			err = s.unpipeline(task)
+		case "allocate":
+			err = s.unallocate(task)
+		}
+		if err != nil {
+			glog.Errorf("Failed to discard operation %s of task <%v/%v>: %v",
+				op.name, task.Namespace, task.Name, err)
 		}
 	}
 }
@@ -212,6 +366,16 @@ func (s *Statement) Commit() {
 			s.evict(op.args[0].(*api.TaskInfo), op.args[1].(string))
 		case "pipeline":
 			s.pipeline(op.args[0].(*api.TaskInfo))
+		case "allocate":
+			task := op.args[0].(*api.TaskInfo)
+			// Only the Allocated tasks are bound, as Session.Allocate does;
+			// e.g. the tasks allocated over backfill are kept in session.
+			if task.Status != api.Allocated {
+				glog.V(3).Infof("Skip binding task <%v/%v> in status %v.",
+					task.Namespace, task.Name, task.Status)
+				continue
+			}
+			s.allocate(task)
 		}
 	}
 }
@@ -0,0 +1,126 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+)
+
+func TestStatementAllocate(t *testing.T) {
+	erp := &eventRecorderPlugin{}
+	defer CleanupPluginBuilders()
+
+	ssn := openTestSession(erp)
+	defer CloseSession(ssn)
+
+	tasks := sessionTasks(ssn)
+	node := ssn.Nodes["n1"]
+	idle := node.Idle.Clone()
+
+	// Allocation is rolled back by discard.
+	stmt := ssn.Statement()
+	if err := stmt.Allocate(tasks["p1"], "n1", false); err != nil {
+		t.Fatalf("failed to allocate p1: %v", err)
+	}
+	if tasks["p1"].Status != api.Allocated {
+		t.Errorf("expected p1 is %v, got %v", api.Allocated, tasks["p1"].Status)
+	}
+	stmt.Discard()
+
+	if tasks["p1"].Status != api.Pending {
+		t.Errorf("expected p1 is %v after discard, got %v", api.Pending, tasks["p1"].Status)
+	}
+	if !reflect.DeepEqual(node.Idle, idle) {
+		t.Errorf("expected idle of n1 is %v after discard, got %v", idle, node.Idle)
+	}
+	if len(tasks["p1"].NodeName) != 0 {
+		t.Errorf("expected node of p1 is cleared after discard, got %s", tasks["p1"].NodeName)
+	}
+	vb := ssn.cache.(*cache.SchedulerCache).VolumeBinder.(*fakeVolumeBinder)
+	if !reflect.DeepEqual(vb.reverted, []string{"p1"}) {
+		t.Errorf("expected volumes of p1 are reverted after discard, got %v", vb.reverted)
+	}
+
+	// Tasks are only bound on commit, even if the job is ready.
+	stmt = ssn.Statement()
+	for _, name := range []string{"p1", "p2"} {
+		if err := stmt.Allocate(tasks[name], "n1", false); err != nil {
+			t.Fatalf("failed to allocate %s: %v", name, err)
+		}
+		if tasks[name].Status != api.Allocated {
+			t.Errorf("expected %s is %v before commit, got %v", name, api.Allocated, tasks[name].Status)
+		}
+	}
+	stmt.Commit()
+
+	for _, name := range []string{"p1", "p2"} {
+		if tasks[name].Status != api.Binding {
+			t.Errorf("expected %s is %v after commit, got %v", name, api.Binding, tasks[name].Status)
+		}
+	}
+
+	// Allocation which failed to bind is rolled back on commit.
+	stmt = ssn.Statement()
+	if err := stmt.Allocate(tasks["p4"], "n1", false); err != nil {
+		t.Fatalf("failed to allocate p4: %v", err)
+	}
+	stmt.Commit()
+
+	if tasks["p4"].Status != api.Pending {
+		t.Errorf("expected p4 is %v after failed commit, got %v", api.Pending, tasks["p4"].Status)
+	}
+
+	// Tasks allocated over backfill are not bound on commit.
+	stmt = ssn.Statement()
+	if err := stmt.Allocate(tasks["p3"], "n1", true); err != nil {
+		t.Fatalf("failed to allocate p3: %v", err)
+	}
+	stmt.Commit()
+
+	if tasks["p3"].Status != api.AllocatedOverBackfill {
+		t.Errorf("expected p3 is %v after commit, got %v", api.AllocatedOverBackfill, tasks["p3"].Status)
+	}
+
+	expected := []string{
+		"allocated p1",
+		"Allocate pg1/p1 on n1",
+		"deallocated p1",
+		"Unallocate pg1/p1 on n1",
+		"allocated p1",
+		"Allocate pg1/p1 on n1",
+		"allocated p2",
+		"Allocate pg1/p2 on n1",
+		"JobReady pg1/p2 on n1",
+		"Dispatch pg1/p1 on n1",
+		"Dispatch pg1/p2 on n1",
+		"allocated p4",
+		"Allocate pg2/p4 on n1",
+		"JobReady pg2/p4 on n1",
+		"BindFailed pg2/p4 on n1: failed to bind volumes of p4",
+		"deallocated p4",
+		"Unallocate pg2/p4 on n1",
+		"allocated p3",
+		"Allocate pg2/p3 on n1",
+	}
+	if !reflect.DeepEqual(erp.events, expected) {
+		t.Errorf("expected events:\n%v\ngot:\n%v", expected, erp.events)
+	}
+}
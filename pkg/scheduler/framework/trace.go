@@ -0,0 +1,250 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+)
+
+// Trace is the scheduling decisions made in a session, which explain why
+// jobs are pending. The methods of Trace are safe to be called concurrently,
+// and do nothing on nil Trace.
+type Trace struct {
+	sync.Mutex `json:"-"`
+
+	SessionUID types.UID `json:"sessionUID"`
+	StartTime  time.Time `json:"startTime"`
+
+	// Jobs are the decisions of jobs, by the "namespace/name" of PodGroup.
+	Jobs map[string]*JobTrace `json:"jobs"`
+	// Queues are the decisions of queues, by queue name.
+	Queues map[string]*QueueTrace `json:"queues"`
+}
+
+// JobTrace is the scheduling decisions of a job in session.
+type JobTrace struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Queue     string `json:"queue"`
+
+	// Invalid is why job was not valid to be scheduled in session, e.g. the
+	// NotEnoughTasks of gang, so it was not scheduled at all.
+	Invalid string `json:"invalid,omitempty"`
+
+	// PredicateTask is the "namespace/name" of the last task of job
+	// predicated, which PredicateFailures are of.
+	PredicateTask string `json:"predicateTask,omitempty"`
+	// PredicateFailures are the reasons why the last task of job predicated
+	// on each node can not be placed on it, by node name.
+	PredicateFailures map[string]string `json:"predicateFailures,omitempty"`
+	// Scores are the final scores of the feasible nodes of the last task of
+	// job prioritized, by node name.
+	Scores map[string]int `json:"scores,omitempty"`
+
+	// Ready is whether job is ready by gang scheduling at the end of session.
+	Ready bool `json:"ready"`
+	// MinAvailable is the minimal number of tasks to run the job.
+	MinAvailable int32 `json:"minAvailable"`
+	// Tasks are the number of tasks of job by status at the end of session.
+	Tasks map[string]int `json:"tasks,omitempty"`
+	// FitError is the summary of resource shortfalls on nodes.
+	FitError string `json:"fitError,omitempty"`
+}
+
+// QueueTrace is the scheduling decisions of a queue in session.
+type QueueTrace struct {
+	// Overused is whether the queue was overused when it was checked last
+	// time in session, so its jobs were not allocated.
+	Overused bool `json:"overused"`
+}
+
+func newTrace(uid types.UID) *Trace {
+	return &Trace{
+		SessionUID: uid,
+		StartTime:  time.Now(),
+		Jobs:       map[string]*JobTrace{},
+		Queues:     map[string]*QueueTrace{},
+	}
+}
+
+// jobKey returns the key of job in trace, the "namespace/name" of PodGroup.
+func jobKey(job *api.JobInfo) string {
+	return fmt.Sprintf("%s/%s", job.Namespace, job.Name)
+}
+
+// job returns the trace of job, which is created if not found; it's
+// called with lock held.
+func (t *Trace) job(job *api.JobInfo) *JobTrace {
+	key := jobKey(job)
+	jt, found := t.Jobs[key]
+	if !found {
+		jt = &JobTrace{
+			Namespace:         job.Namespace,
+			Name:              job.Name,
+			Queue:             string(job.Queue),
+			PredicateFailures: map[string]string{},
+		}
+		t.Jobs[key] = jt
+	}
+	return jt
+}
+
+// recordPredicate records the predicate result of the task of job on node;
+// the failures of the previous task of job are dropped.
+func (t *Trace) recordPredicate(job *api.JobInfo, task *api.TaskInfo, nodeName string, err error) {
+	if t == nil || job == nil {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	jt := t.job(job)
+	if key := fmt.Sprintf("%s/%s", task.Namespace, task.Name); jt.PredicateTask != key {
+		jt.PredicateTask = key
+		jt.PredicateFailures = map[string]string{}
+	}
+	if err != nil {
+		jt.PredicateFailures[nodeName] = err.Error()
+	} else {
+		delete(jt.PredicateFailures, nodeName)
+	}
+}
+
+// recordPredicates records the predicate results of the task of job on
+// nodes, which replace the failures of the previous predicates of job.
+func (t *Trace) recordPredicates(job *api.JobInfo, task *api.TaskInfo, nodes []*api.NodeInfo, errs []error) {
+	if t == nil || job == nil {
+		return
+	}
+
+	failures := map[string]string{}
+	for i, err := range errs {
+		if err != nil {
+			failures[nodes[i].Name] = err.Error()
+		}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	jt := t.job(job)
+	jt.PredicateTask = fmt.Sprintf("%s/%s", task.Namespace, task.Name)
+	jt.PredicateFailures = failures
+}
+
+// recordScores records the final node scores of the task of job.
+func (t *Trace) recordScores(job *api.JobInfo, scores map[string]int) {
+	if t == nil || job == nil {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	t.job(job).Scores = scores
+}
+
+// recordOverused records whether queue is overused.
+func (t *Trace) recordOverused(queue *api.QueueInfo, overused bool) {
+	if t == nil || queue == nil {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	t.Queues[queue.Name] = &QueueTrace{Overused: overused}
+}
+
+// recordInvalid records why job is not valid to be scheduled in session.
+func (t *Trace) recordInvalid(job *api.JobInfo, result *api.ValidateResult) {
+	if t == nil || job == nil {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	jt := t.job(job)
+	jt.Invalid = fmt.Sprintf("%s: %s", result.Reason, result.Message)
+	jt.MinAvailable = job.MinAvailable
+	jt.Tasks = taskNums(job)
+}
+
+// taskNums returns the number of tasks of job by status.
+func taskNums(job *api.JobInfo) map[string]int {
+	nums := map[string]int{}
+	for status, tasks := range job.TaskStatusIndex {
+		if len(tasks) != 0 {
+			nums[status.String()] = len(tasks)
+		}
+	}
+	return nums
+}
+
+// recordJobs records the readiness and task status of jobs in session.
+func (t *Trace) recordJobs(ssn *Session) {
+	if t == nil {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	for _, job := range ssn.Jobs {
+		jt := t.job(job)
+		jt.Ready = ssn.JobReady(job)
+		jt.MinAvailable = job.MinAvailable
+		jt.Tasks = taskNums(job)
+		if !jt.Ready {
+			jt.FitError = job.FitError()
+		}
+	}
+}
+
+// Job returns the trace of job by the "namespace/name" of PodGroup.
+func (t *Trace) Job(key string) (*JobTrace, bool) {
+	if t == nil {
+		return nil, false
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	jt, found := t.Jobs[key]
+	return jt, found
+}
+
+// Queue returns the trace of queue by name.
+func (t *Trace) Queue(name string) (*QueueTrace, bool) {
+	if t == nil {
+		return nil, false
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	qt, found := t.Queues[name]
+	return qt, found
+}
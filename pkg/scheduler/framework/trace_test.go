@@ -0,0 +1,137 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+)
+
+// tracePlugin makes jobs ready by their min member, rejects job pg2 as not
+// valid, and filters out all nodes for the tasks other than p1.
+type tracePlugin struct{}
+
+func (tp *tracePlugin) Name() string {
+	return "trace"
+}
+
+func (tp *tracePlugin) OnSessionOpen(ssn *Session) {
+	ssn.AddJobReadyFn(tp.Name(), func(obj interface{}) api.JobReadiness {
+		return obj.(*api.JobInfo).GetReadiness()
+	})
+	ssn.AddJobValidFn(tp.Name(), func(obj interface{}) *api.ValidateResult {
+		if obj.(*api.JobInfo).Name == "pg2" {
+			return &api.ValidateResult{Reason: "NotEnoughTasks", Message: "not enough tasks"}
+		}
+		return nil
+	})
+	ssn.AddPredicateFn(tp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) error {
+		if task.Name != "p1" {
+			return fmt.Errorf("node <%s> is reserved", node.Name)
+		}
+		return nil
+	})
+}
+
+func (tp *tracePlugin) OnSessionClose(ssn *Session) {}
+
+func TestTrace(t *testing.T) {
+	defer CleanupPluginBuilders()
+
+	ssn := openTestSession(&tracePlugin{})
+	tasks := sessionTasks(ssn)
+	n1 := ssn.Nodes["n1"]
+	n2 := api.NewNodeInfo(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n2"}})
+
+	if err := ssn.PredicateFn(tasks["p2"], n2); err == nil {
+		t.Errorf("expected p2 is filtered out")
+	}
+	pg1, found := ssn.Trace.Job("c1/pg1")
+	if !found {
+		t.Fatalf("expected trace of pg1")
+	}
+	if reason := pg1.PredicateFailures["n2"]; reason != "node <n2> is reserved" {
+		t.Errorf("expected predicate failure of p2 on n2, got %q", reason)
+	}
+	// The failures of all nodes evaluated are recorded at once.
+	if found := ssn.PredicateNodes(tasks["p2"], []*api.NodeInfo{n1, n2}, 1, 2); len(found) != 0 {
+		t.Errorf("expected no node is feasible for p2, got %d", len(found))
+	}
+	pg1, _ = ssn.Trace.Job("c1/pg1")
+	if len(pg1.PredicateFailures) != 2 {
+		t.Errorf("expected predicate failures of p2 on n1 and n2, got %v", pg1.PredicateFailures)
+	}
+	// The failures of p2 are dropped once p1 is predicated.
+	if err := ssn.PredicateFn(tasks["p1"], n1); err != nil {
+		t.Errorf("expected p1 fits n1, got %v", err)
+	}
+	ssn.PrioritizeNodes(tasks["p1"], []*api.NodeInfo{n1}, 1)
+	ssn.Overused(ssn.Queues["c1"])
+	if err := ssn.Allocate(tasks["p1"], "n1", false); err != nil {
+		t.Fatalf("failed to allocate p1: %v", err)
+	}
+
+	CloseSession(ssn)
+	trace := ssn.Trace
+
+	pg1, found = trace.Job("c1/pg1")
+	if !found {
+		t.Fatalf("expected trace of pg1")
+	}
+	expected := &JobTrace{
+		Namespace:         "c1",
+		Name:              "pg1",
+		Queue:             "c1",
+		PredicateTask:     "c1/p1",
+		PredicateFailures: map[string]string{},
+		Scores:            map[string]int{"n1": 0},
+		Ready:             false,
+		MinAvailable:      2,
+		Tasks:             map[string]int{"Allocated": 1, "Pending": 1},
+		FitError:          pg1.FitError,
+	}
+	if !reflect.DeepEqual(pg1, expected) {
+		t.Errorf("expected trace of pg1 %+v, got %+v", expected, pg1)
+	}
+
+	pg2, found := trace.Job("c1/pg2")
+	if !found {
+		t.Fatalf("expected trace of pg2")
+	}
+	expected = &JobTrace{
+		Namespace:         "c1",
+		Name:              "pg2",
+		Queue:             "c1",
+		Invalid:           "NotEnoughTasks: not enough tasks",
+		PredicateFailures: map[string]string{},
+		MinAvailable:      1,
+		Tasks:             map[string]int{"Pending": 2},
+	}
+	if !reflect.DeepEqual(pg2, expected) {
+		t.Errorf("expected trace of pg2 %+v, got %+v", expected, pg2)
+	}
+
+	if qt, found := trace.Queue("c1"); !found || qt.Overused {
+		t.Errorf("expected queue c1 is not overused, got %+v", qt)
+	}
+}
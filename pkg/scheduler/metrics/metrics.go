@@ -62,6 +62,14 @@ var (
 		}, []string{"action"},
 	)
 
+	actionSkippedCount = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: KubeBatchNamespace,
+			Name:      "action_skipped_total",
+			Help:      "Number of scheduling cycles in which the action is skipped because its interval is not elapsed",
+		}, []string{"action"},
+	)
+
 	taskSchedulingLatency = promauto.NewHistogram(
 		prometheus.HistogramOpts{
 			Subsystem: KubeBatchNamespace,
@@ -111,6 +119,14 @@ var (
 		},
 	)
 
+	confReloadCount = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: KubeBatchNamespace,
+			Name:      "scheduler_conf_reload_total",
+			Help:      "Number of scheduler configuration reloads, by the result: 'success' or 'failure'",
+		}, []string{"result"},
+	)
+
 	jobRetryCount = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Subsystem: KubeBatchNamespace,
@@ -130,6 +146,11 @@ func UpdateActionDuration(actionName string, duration time.Duration) {
 	actionSchedulingLatency.WithLabelValues(actionName).Observe(DurationInMicroseconds(duration))
 }
 
+// RegisterActionSkipped records a skipped run of the action
+func RegisterActionSkipped(actionName string) {
+	actionSkippedCount.WithLabelValues(actionName).Inc()
+}
+
 // UpdateE2eDuration updates entire end to end scheduling latency
 func UpdateE2eDuration(duration time.Duration) {
 	e2eSchedulingLatency.Observe(DurationInMilliseconds(duration))
@@ -170,6 +191,11 @@ func RegisterJobRetries(jobID string) {
 	jobRetryCount.WithLabelValues(jobID).Inc()
 }
 
+// RegisterConfReload records a reload of scheduler configuration by the result.
+func RegisterConfReload(result string) {
+	confReloadCount.WithLabelValues(result).Inc()
+}
+
 // DurationInMicroseconds gets the time in microseconds.
 func DurationInMicroseconds(duration time.Duration) float64 {
 	return float64(duration.Nanoseconds()) / float64(time.Microsecond.Nanoseconds())
@@ -24,6 +24,9 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
 )
 
+// Arguments is the argument schema of conformance plugin, which takes no argument.
+var Arguments = framework.ArgumentSchema{}
+
 type conformancePlugin struct {
 	// Arguments given for the plugin
 	pluginArguments map[string]string
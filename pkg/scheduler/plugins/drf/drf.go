@@ -34,6 +34,9 @@ type drfAttr struct {
 	allocated        *api.Resource
 }
 
+// Arguments is the argument schema of drf plugin, which takes no argument.
+var Arguments = framework.ArgumentSchema{}
+
 type drfPlugin struct {
 	totalResource *api.Resource
 
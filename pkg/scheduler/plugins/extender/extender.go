@@ -0,0 +1,295 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extender
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+const (
+	// URLPrefix is the key for providing the URL prefix of extender in YAML
+	URLPrefix = "extender.urlPrefix"
+	// FilterVerb is the key for providing the verb of filter call in YAML; no filter call if not set
+	FilterVerb = "extender.filterVerb"
+	// PrioritizeVerb is the key for providing the verb of prioritize call in YAML; no prioritize call if not set
+	PrioritizeVerb = "extender.prioritizeVerb"
+	// HTTPTimeout is the key for providing the timeout of calls to extender in YAML
+	HTTPTimeout = "extender.httpTimeout"
+	// NodeCacheCapable is the key for providing whether extender caches nodes in YAML;
+	// only node names are sent to extender if true
+	NodeCacheCapable = "extender.nodeCacheCapable"
+	// Ignorable is the key for providing whether the failure of extender is ignored in YAML
+	Ignorable = "extender.ignorable"
+
+	// defaultHTTPTimeout is the default timeout of calls to extender, the same as kube-scheduler.
+	defaultHTTPTimeout = 5 * time.Second
+)
+
+// Arguments is the argument schema of extender plugin.
+var Arguments = framework.ArgumentSchema{
+	URLPrefix:        framework.ArgumentString,
+	FilterVerb:       framework.ArgumentString,
+	PrioritizeVerb:   framework.ArgumentString,
+	HTTPTimeout:      framework.ArgumentDuration,
+	NodeCacheCapable: framework.ArgumentBool,
+	Ignorable:        framework.ArgumentBool,
+}
+
+// filterResult is the result of filter call for a task.
+type filterResult struct {
+	// passed are the nodes which the task can be placed on.
+	passed map[string]bool
+	// failed are the nodes which are filtered out, with the reasons.
+	failed map[string]string
+	err    error
+}
+
+// prioritizeResult is the result of prioritize call for a task.
+type prioritizeResult struct {
+	scores map[string]int
+	err    error
+}
+
+type extenderPlugin struct {
+	urlPrefix        string
+	filterVerb       string
+	prioritizeVerb   string
+	nodeCacheCapable bool
+	ignorable        bool
+	client           *http.Client
+
+	// The results of extender are cached by task, so there's one call for
+	// all nodes of each task; they're dropped once any task is allocated
+	// or deallocated.
+	filterResults     map[api.TaskID]*filterResult
+	prioritizeResults map[api.TaskID]*prioritizeResult
+
+	// Arguments given for the plugin
+	pluginArguments map[string]string
+}
+
+func New(arguments map[string]string) framework.Plugin {
+	ep := &extenderPlugin{
+		urlPrefix:         strings.TrimRight(arguments[URLPrefix], "/"),
+		filterVerb:        strings.Trim(arguments[FilterVerb], "/"),
+		prioritizeVerb:    strings.Trim(arguments[PrioritizeVerb], "/"),
+		filterResults:     map[api.TaskID]*filterResult{},
+		prioritizeResults: map[api.TaskID]*prioritizeResult{},
+		pluginArguments:   arguments,
+	}
+
+	timeout := defaultHTTPTimeout
+	if value := arguments[HTTPTimeout]; len(value) != 0 {
+		if d, err := time.ParseDuration(value); err != nil {
+			glog.Warningf("Could not parse argument %s: %s as duration, %v", HTTPTimeout, value, err)
+		} else {
+			timeout = d
+		}
+	}
+	ep.client = &http.Client{Timeout: timeout}
+
+	if value := arguments[NodeCacheCapable]; len(value) != 0 {
+		ep.nodeCacheCapable, _ = strconv.ParseBool(value)
+	}
+	if value := arguments[Ignorable]; len(value) != 0 {
+		ep.ignorable, _ = strconv.ParseBool(value)
+	}
+
+	return ep
+}
+
+func (ep *extenderPlugin) Name() string {
+	return "extender"
+}
+
+// send posts args to the verb of extender, and decodes the response into result.
+func (ep *extenderPlugin) send(verb string, args interface{}, result interface{}) error {
+	body, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+
+	url := ep.urlPrefix + "/" + verb
+	resp, err := ep.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed %v with extender at URL %v, code %v", verb, url, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// extenderArgs builds the arguments of extender call with all nodes in session.
+func (ep *extenderPlugin) extenderArgs(ssn *framework.Session, task *api.TaskInfo) *schedulerapi.ExtenderArgs {
+	args := &schedulerapi.ExtenderArgs{Pod: task.Pod}
+
+	if ep.nodeCacheCapable {
+		nodeNames := make([]string, 0, len(ssn.Nodes))
+		for name := range ssn.Nodes {
+			nodeNames = append(nodeNames, name)
+		}
+		args.NodeNames = &nodeNames
+	} else {
+		nodes := &v1.NodeList{}
+		for _, node := range ssn.Nodes {
+			if node.Node != nil {
+				nodes.Items = append(nodes.Items, *node.Node)
+			}
+		}
+		args.Nodes = nodes
+	}
+
+	return args
+}
+
+func (ep *extenderPlugin) filter(ssn *framework.Session, task *api.TaskInfo) *filterResult {
+	if result, found := ep.filterResults[task.UID]; found {
+		return result
+	}
+
+	result := &filterResult{
+		passed: map[string]bool{},
+		failed: map[string]string{},
+	}
+
+	extenderResult := &schedulerapi.ExtenderFilterResult{}
+	if err := ep.send(ep.filterVerb, ep.extenderArgs(ssn, task), extenderResult); err != nil {
+		result.err = err
+	} else if len(extenderResult.Error) != 0 {
+		result.err = fmt.Errorf("%s", extenderResult.Error)
+	} else {
+		if extenderResult.NodeNames != nil {
+			for _, name := range *extenderResult.NodeNames {
+				result.passed[name] = true
+			}
+		} else if extenderResult.Nodes != nil {
+			for _, node := range extenderResult.Nodes.Items {
+				result.passed[node.Name] = true
+			}
+		}
+		for name, reason := range extenderResult.FailedNodes {
+			result.failed[name] = reason
+		}
+	}
+
+	if result.err != nil {
+		glog.Errorf("Failed to filter nodes by extender for task <%s/%s>: %v",
+			task.Namespace, task.Name, result.err)
+	}
+
+	ep.filterResults[task.UID] = result
+	return result
+}
+
+func (ep *extenderPlugin) prioritize(ssn *framework.Session, task *api.TaskInfo) *prioritizeResult {
+	if result, found := ep.prioritizeResults[task.UID]; found {
+		return result
+	}
+
+	result := &prioritizeResult{
+		scores: map[string]int{},
+	}
+
+	hostPriorities := schedulerapi.HostPriorityList{}
+	if err := ep.send(ep.prioritizeVerb, ep.extenderArgs(ssn, task), &hostPriorities); err != nil {
+		result.err = err
+		glog.Errorf("Failed to prioritize nodes by extender for task <%s/%s>: %v",
+			task.Namespace, task.Name, err)
+	} else {
+		for _, hostPriority := range hostPriorities {
+			result.scores[hostPriority.Host] = hostPriority.Score
+		}
+	}
+
+	ep.prioritizeResults[task.UID] = result
+	return result
+}
+
+func (ep *extenderPlugin) OnSessionOpen(ssn *framework.Session) {
+	if len(ep.urlPrefix) == 0 {
+		glog.Warningf("No %s is given to extender plugin, ignore it.", URLPrefix)
+		return
+	}
+
+	if len(ep.filterVerb) != 0 {
+		ssn.AddPredicateFn(ep.Name(), func(task *api.TaskInfo, node *api.NodeInfo) error {
+			result := ep.filter(ssn, task)
+			if result.err != nil {
+				if ep.ignorable {
+					return nil
+				}
+				return fmt.Errorf("extender failed to filter nodes: %v", result.err)
+			}
+
+			if reason, found := result.failed[node.Name]; found {
+				return fmt.Errorf("node <%s> is filtered out by extender: %s", node.Name, reason)
+			}
+			if !result.passed[node.Name] {
+				return fmt.Errorf("node <%s> is filtered out by extender", node.Name)
+			}
+
+			return nil
+		})
+	}
+
+	if len(ep.prioritizeVerb) != 0 {
+		ssn.AddNodeOrderFn(ep.Name(), func(task *api.TaskInfo, node *api.NodeInfo) (int, error) {
+			result := ep.prioritize(ssn, task)
+			if result.err != nil {
+				if ep.ignorable {
+					return 0, nil
+				}
+				return 0, fmt.Errorf("extender failed to prioritize nodes: %v", result.err)
+			}
+
+			return result.scores[node.Name], nil
+		})
+	}
+
+	// Nodes are changed by allocation, so call extender again afterwards.
+	invalidate := func(event *framework.Event) {
+		ep.filterResults = map[api.TaskID]*filterResult{}
+		ep.prioritizeResults = map[api.TaskID]*prioritizeResult{}
+	}
+	ssn.AddEventHandler(&framework.EventHandler{
+		AllocateFunc:   invalidate,
+		DeallocateFunc: invalidate,
+	})
+}
+
+func (ep *extenderPlugin) OnSessionClose(ssn *framework.Session) {
+	ep.filterResults = nil
+	ep.prioritizeResults = nil
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extender
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+func buildResourceList(cpu string, memory string) v1.ResourceList {
+	return v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+func buildNode(name string, alloc v1.ResourceList) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: v1.NodeStatus{
+			Capacity:    alloc,
+			Allocatable: alloc,
+		},
+	}
+}
+
+func buildPod(ns, n string, req v1.ResourceList, groupName string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       types.UID(fmt.Sprintf("%v-%v", ns, n)),
+			Name:      n,
+			Namespace: ns,
+			Annotations: map[string]string{
+				kbv1.GroupNameAnnotationKey: groupName,
+			},
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodPending,
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: req,
+					},
+				},
+			},
+			Priority: new(int32),
+		},
+	}
+}
+
+type fakeStatusUpdater struct {
+}
+
+func (ftsu *fakeStatusUpdater) UpdatePodCondition(pod *v1.Pod, podCondition *v1.PodCondition) (*v1.Pod, error) {
+	// do nothing here
+	return pod, nil
+}
+
+func (ftsu *fakeStatusUpdater) UpdatePodGroup(pg *kbv1.PodGroup) (*kbv1.PodGroup, error) {
+	// do nothing here
+	return pg, nil
+}
+
+func (ftsu *fakeStatusUpdater) UpdateQueueStatus(queue *kbv1.Queue) (*kbv1.Queue, error) {
+	// do nothing here
+	return queue, nil
+}
+
+// fakeExtender filters out node n2 and prefers node n3.
+type fakeExtender struct {
+	filterCalls     int32
+	prioritizeCalls int32
+	delay           time.Duration
+	status          int
+}
+
+func (fe *fakeExtender) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(fe.delay)
+	if fe.status != 0 {
+		w.WriteHeader(fe.status)
+		return
+	}
+
+	args := &schedulerapi.ExtenderArgs{}
+	if err := json.NewDecoder(r.Body).Decode(args); err != nil || args.Pod == nil || args.Nodes == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var result interface{}
+	switch r.URL.Path {
+	case "/filter":
+		atomic.AddInt32(&fe.filterCalls, 1)
+		filterResult := &schedulerapi.ExtenderFilterResult{
+			Nodes:       &v1.NodeList{},
+			FailedNodes: schedulerapi.FailedNodesMap{},
+		}
+		for _, node := range args.Nodes.Items {
+			if node.Name == "n2" {
+				filterResult.FailedNodes[node.Name] = "no licence"
+			} else {
+				filterResult.Nodes.Items = append(filterResult.Nodes.Items, node)
+			}
+		}
+		result = filterResult
+	case "/prioritize":
+		atomic.AddInt32(&fe.prioritizeCalls, 1)
+		hostPriorities := schedulerapi.HostPriorityList{}
+		for _, node := range args.Nodes.Items {
+			score := 1
+			if node.Name == "n3" {
+				score = 5
+			}
+			hostPriorities = append(hostPriorities, schedulerapi.HostPriority{Host: node.Name, Score: score})
+		}
+		result = hostPriorities
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	json.NewEncoder(w).Encode(result)
+}
+
+func openSession(arguments map[string]string) *framework.Session {
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Queues:        make(map[api.QueueID]*api.QueueInfo),
+		StatusUpdater: &fakeStatusUpdater{},
+		Recorder:      record.NewFakeRecorder(100),
+	}
+	for _, name := range []string{"n1", "n2", "n3"} {
+		schedulerCache.AddNode(buildNode(name, buildResourceList("2", "4G")))
+	}
+	schedulerCache.AddPod(buildPod("c1", "p1", buildResourceList("1", "1G"), "pg1"))
+	schedulerCache.AddPod(buildPod("c1", "p2", buildResourceList("1", "1G"), "pg1"))
+	schedulerCache.AddPodGroup(&kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pg1",
+			Namespace: "c1",
+		},
+		Spec: kbv1.PodGroupSpec{
+			Queue: "q1",
+		},
+	})
+	schedulerCache.AddQueue(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "q1",
+		},
+	})
+
+	return framework.OpenSession(schedulerCache, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:      "extender",
+					Arguments: arguments,
+				},
+			},
+		},
+	})
+}
+
+func TestExtender(t *testing.T) {
+	framework.RegisterPluginBuilder("extender", New)
+	defer framework.CleanupPluginBuilders()
+
+	extender := &fakeExtender{}
+	server := httptest.NewServer(extender)
+	defer server.Close()
+
+	ssn := openSession(map[string]string{
+		URLPrefix:      server.URL,
+		FilterVerb:     "filter",
+		PrioritizeVerb: "prioritize",
+	})
+	defer framework.CloseSession(ssn)
+
+	expectedFit := map[string]bool{"n1": true, "n2": false, "n3": true}
+	expectedScores := map[string]int{"n1": 1, "n3": 5}
+
+	for _, task := range ssn.Jobs["c1/pg1"].TaskStatusIndex[api.Pending] {
+		for name, expected := range expectedFit {
+			if err := ssn.PredicateFn(task, ssn.Nodes[name]); (err == nil) != expected {
+				t.Errorf("task <%s>: expected predicate on node <%s> to be %v, got error %v",
+					task.Name, name, expected, err)
+			}
+		}
+		for name, expected := range expectedScores {
+			if score, err := ssn.NodeOrderFn(task, ssn.Nodes[name]); err != nil || score != expected {
+				t.Errorf("task <%s>: expected score %d on node <%s>, got %d, %v",
+					task.Name, expected, name, score, err)
+			}
+		}
+	}
+
+	// One call for all nodes of each task.
+	if calls := atomic.LoadInt32(&extender.filterCalls); calls != 2 {
+		t.Errorf("expected 2 filter calls, got %d", calls)
+	}
+	if calls := atomic.LoadInt32(&extender.prioritizeCalls); calls != 2 {
+		t.Errorf("expected 2 prioritize calls, got %d", calls)
+	}
+}
+
+func TestExtenderFailure(t *testing.T) {
+	framework.RegisterPluginBuilder("extender", New)
+	defer framework.CleanupPluginBuilders()
+
+	tests := []struct {
+		name      string
+		extender  *fakeExtender
+		ignorable string
+		fit       bool
+	}{
+		{
+			name:      "error status",
+			extender:  &fakeExtender{status: http.StatusInternalServerError},
+			ignorable: "false",
+			fit:       false,
+		},
+		{
+			name:      "ignorable error status",
+			extender:  &fakeExtender{status: http.StatusInternalServerError},
+			ignorable: "true",
+			fit:       true,
+		},
+		{
+			name:      "timeout",
+			extender:  &fakeExtender{delay: 200 * time.Millisecond},
+			ignorable: "false",
+			fit:       false,
+		},
+		{
+			name:      "ignorable timeout",
+			extender:  &fakeExtender{delay: 200 * time.Millisecond},
+			ignorable: "true",
+			fit:       true,
+		},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(test.extender)
+
+		ssn := openSession(map[string]string{
+			URLPrefix:   server.URL,
+			FilterVerb:  "filter",
+			HTTPTimeout: "50ms",
+			Ignorable:   test.ignorable,
+		})
+
+		task := ssn.Jobs["c1/pg1"].TaskStatusIndex[api.Pending]["c1-p1"]
+		if err := ssn.PredicateFn(task, ssn.Nodes["n1"]); (err == nil) != test.fit {
+			t.Errorf("case <%s>: expected predicate to be %v, got error %v", test.name, test.fit, err)
+		}
+
+		framework.CloseSession(ssn)
+		server.Close()
+	}
+}
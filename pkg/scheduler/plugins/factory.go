@@ -21,11 +21,13 @@ import (
 
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/conformance"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/drf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/extender"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/gang"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/nodeorder"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/predicates"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/priority"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/proportion"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins/topology"
 )
 
 func init() {
@@ -36,7 +38,20 @@ func init() {
 	framework.RegisterPluginBuilder("priority", priority.New)
 	framework.RegisterPluginBuilder("nodeorder", nodeorder.New)
 	framework.RegisterPluginBuilder("conformance", conformance.New)
+	framework.RegisterPluginBuilder("topology", topology.New)
+	framework.RegisterPluginBuilder("extender", extender.New)
 
 	// Plugins for Queues
 	framework.RegisterPluginBuilder("proportion", proportion.New)
+
+	// Argument schemas of plugins
+	framework.RegisterPluginArguments("drf", drf.Arguments)
+	framework.RegisterPluginArguments("gang", gang.Arguments)
+	framework.RegisterPluginArguments("predicates", predicates.Arguments)
+	framework.RegisterPluginArguments("priority", priority.Arguments)
+	framework.RegisterPluginArguments("nodeorder", nodeorder.Arguments)
+	framework.RegisterPluginArguments("conformance", conformance.Arguments)
+	framework.RegisterPluginArguments("topology", topology.Arguments)
+	framework.RegisterPluginArguments("extender", extender.Arguments)
+	framework.RegisterPluginArguments("proportion", proportion.Arguments)
 }
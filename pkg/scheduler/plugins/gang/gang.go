@@ -18,6 +18,10 @@ package gang
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/golang/glog"
 
@@ -30,6 +34,9 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/metrics"
 )
 
+// Arguments is the argument schema of gang plugin, which takes no argument.
+var Arguments = framework.ArgumentSchema{}
+
 type gangPlugin struct {
 	// Arguments given for the plugin
 	pluginArguments map[string]string
@@ -59,6 +66,135 @@ func validTaskNum(job *api.JobInfo) int32 {
 	return int32(occupied)
 }
 
+// validTaskNumOfRoles return the number of tasks that are valid of each role.
+func validTaskNumOfRoles(job *api.JobInfo) map[string]int32 {
+	statuses := append(api.AllocatedStatuses(),
+		api.AllocatedOverBackfill, api.Succeeded, api.Pipelined, api.Pending)
+	return job.TaskNumOfRoles(statuses...)
+}
+
+// readyTaskNumOfRoles return the number of tasks that are ready of each role.
+func readyTaskNumOfRoles(job *api.JobInfo) map[string]int32 {
+	statuses := append(api.AllocatedStatuses(), api.Succeeded, api.Pipelined)
+	return job.TaskNumOfRoles(statuses...)
+}
+
+// unmetRoles returns the description of roles whose minimal member is not met.
+func unmetRoles(job *api.JobInfo, nums map[string]int32) string {
+	var roles []string
+	for role, min := range job.MinTaskMember {
+		if nums[role] < min {
+			roles = append(roles, fmt.Sprintf("%s: %d/%d", role, nums[role], min))
+		}
+	}
+	sort.Strings(roles)
+
+	return strings.Join(roles, ", ")
+}
+
+// waitingForPods returns whether the controller may delay creating pods of the job:
+// the PodGroup with `spec.minResources` is waiting for being admitted into InQueue,
+// or the controller is creating pods for it.
+func waitingForPods(job *api.JobInfo) bool {
+	if job.PodGroup == nil || job.MinResources == nil {
+		return false
+	}
+
+	return job.PodGroup.Status.Phase == v1alpha1.PodGroupPending ||
+		job.PodGroup.Status.Phase == v1alpha1.PodGroupInQueue
+}
+
+// scheduledCondition returns the Scheduled condition of the job, or nil if the job
+// has never been scheduled.
+func scheduledCondition(job *api.JobInfo) *v1alpha1.PodGroupCondition {
+	for i, c := range job.PodGroup.Status.Conditions {
+		if c.Type == v1alpha1.PodGroupScheduledType {
+			return &job.PodGroup.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// scheduleTimeout returns whether the job was not scheduled before its deadline,
+// which is counted from its creation, or from when it became unscheduled if it
+// was scheduled before.
+func scheduleTimeout(job *api.JobInfo, now time.Time) bool {
+	if job.PodGroup == nil || job.PodGroup.Spec.ScheduleTimeoutSeconds == nil {
+		return false
+	}
+
+	start := job.CreationTimestamp.Time
+	if c := scheduledCondition(job); c != nil {
+		if c.Status == v1.ConditionTrue {
+			return false
+		}
+		start = c.LastTransitionTime.Time
+	}
+
+	timeout := time.Duration(*job.PodGroup.Spec.ScheduleTimeoutSeconds) * time.Second
+	return start.Add(timeout).Before(now)
+}
+
+// updateScheduledCondition records when the job with schedule timeout became
+// scheduled or unscheduled, so its deadline is counted from then.
+func updateScheduledCondition(ssn *framework.Session, job *api.JobInfo) {
+	if job.PodGroup == nil || job.PodGroup.Spec.ScheduleTimeoutSeconds == nil {
+		return
+	}
+
+	status := v1.ConditionFalse
+	if jobReady(job) == api.Ready {
+		status = v1.ConditionTrue
+	}
+
+	c := scheduledCondition(job)
+	// The job is not scheduled yet, or its status is not changed.
+	if (c == nil && status == v1.ConditionFalse) || (c != nil && c.Status == status) {
+		return
+	}
+
+	jc := &v1alpha1.PodGroupCondition{
+		Type:               v1alpha1.PodGroupScheduledType,
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		TransitionID:       string(ssn.UID),
+	}
+	if err := ssn.UpdateJobCondition(job, jc); err != nil {
+		glog.Errorf("Failed to update job <%s/%s> condition: %v",
+			job.Namespace, job.Name, err)
+	}
+}
+
+// failTimeoutJob marks the pending job as failed if it's not scheduled before
+// its deadline, and returns whether it's failed.
+func failTimeoutJob(ssn *framework.Session, job *api.JobInfo) bool {
+	if jobReady(job) == api.Ready || !scheduleTimeout(job, time.Now()) ||
+		(job.PodGroup.Status.Phase != v1alpha1.PodGroupPending &&
+			job.PodGroup.Status.Phase != v1alpha1.PodGroupInQueue) {
+		return false
+	}
+
+	glog.V(3).Infof("Job <%s/%s> was not scheduled in %d seconds, mark it as failed.",
+		job.Namespace, job.Name, *job.PodGroup.Spec.ScheduleTimeoutSeconds)
+
+	job.PodGroup.Status.Phase = v1alpha1.PodGroupFailed
+	jc := &v1alpha1.PodGroupCondition{
+		Type:               v1alpha1.PodGroupFailedType,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		TransitionID:       string(ssn.UID),
+		Reason:             v1alpha1.ScheduleTimeoutReason,
+		Message: fmt.Sprintf("%v/%v tasks in gang were not scheduled in %d seconds",
+			job.MinAvailable-readyTaskNum(job), len(job.Tasks), *job.PodGroup.Spec.ScheduleTimeoutSeconds),
+	}
+	if err := ssn.UpdateJobCondition(job, jc); err != nil {
+		glog.Errorf("Failed to update job <%s/%s> condition: %v",
+			job.Namespace, job.Name, err)
+	}
+
+	return true
+}
+
 // TODO Terry: Remove this function
 func jobReady(obj interface{}) api.JobReadiness {
 	job := obj.(*api.JobInfo)
@@ -79,20 +215,40 @@ func backFillEligible(obj interface{}) bool {
 	return allPending
 }
 
+// feasibleResource returns the upper limit of resources that the jobs of the queue
+// can ever get; the resource not listed in the queue's capability is limited by the
+// total allocatable resources of the cluster.
+func feasibleResource(queue *api.QueueInfo, total *api.Resource) *api.Resource {
+	if queue == nil || len(queue.Queue.Spec.Capability) == 0 {
+		return total
+	}
+
+	capability := queue.Queue.Spec.Capability
+	feasible := total.Clone()
+	if q, found := capability[v1.ResourceCPU]; found {
+		feasible.MilliCPU = math.Min(feasible.MilliCPU, float64(q.MilliValue()))
+	}
+	if q, found := capability[v1.ResourceMemory]; found {
+		feasible.Memory = math.Min(feasible.Memory, float64(q.Value()))
+	}
+	if q, found := capability[api.GPUResourceName]; found {
+		feasible.MilliGPU = math.Min(feasible.MilliGPU, float64(q.MilliValue()))
+	}
+
+	return feasible
+}
+
 func (gp *gangPlugin) OnSessionOpen(ssn *framework.Session) {
 	glog.V(3).Infof("In OnSessionOpen of gangPlugin")
 
-	validJobFn := func(obj interface{}) *api.ValidateResult {
-		job, ok := obj.(*api.JobInfo)
-		if !ok {
-			return &api.ValidateResult{
-				Pass:    false,
-				Message: fmt.Sprintf("Failed to convert <%v> to *JobInfo", obj),
-			}
-		}
+	total := api.EmptyResource()
+	for _, n := range ssn.Nodes {
+		total.Add(n.Allocatable)
+	}
 
+	validJob := func(job *api.JobInfo) *api.ValidateResult {
 		vtn := validTaskNum(job)
-		if vtn < job.MinAvailable {
+		if vtn < job.MinAvailable && !waitingForPods(job) {
 			return &api.ValidateResult{
 				Pass:   false,
 				Reason: v1alpha1.NotEnoughPodsReason,
@@ -100,9 +256,54 @@ func (gp *gangPlugin) OnSessionOpen(ssn *framework.Session) {
 					vtn, job.MinAvailable),
 			}
 		}
+
+		if nums := validTaskNumOfRoles(job); !job.MinTaskMemberMet(nums) && !waitingForPods(job) {
+			return &api.ValidateResult{
+				Pass:   false,
+				Reason: v1alpha1.NotEnoughPodsReason,
+				Message: fmt.Sprintf("Not enough valid tasks of roles for gang-scheduling: %s",
+					unmetRoles(job, nums)),
+			}
+		}
+
+		// Skip the job whose minimal resources can never be satisfied, so that it
+		// will not be tried by actions in every scheduling cycle.
+		if job.MinResources != nil {
+			feasible := feasibleResource(ssn.Queues[job.Queue], total)
+			if !job.MinResources.LessEqual(feasible) {
+				return &api.ValidateResult{
+					Pass:   false,
+					Reason: v1alpha1.InfeasibleResourcesReason,
+					Message: fmt.Sprintf("Min resources <%v> of job can never be satisfied, feasible: <%v>",
+						job.MinResources, feasible),
+				}
+			}
+		}
+
 		return nil
 	}
 
+	validJobFn := func(obj interface{}) *api.ValidateResult {
+		job, ok := obj.(*api.JobInfo)
+		if !ok {
+			return &api.ValidateResult{
+				Pass:    false,
+				Message: fmt.Sprintf("Failed to convert <%v> to *JobInfo", obj),
+			}
+		}
+
+		result := validJob(job)
+		// The rejected job is removed from session before it's closed, so its
+		// schedule timeout is checked here; the status of job is updated when
+		// it's removed.
+		if result != nil && !result.Pass {
+			updateScheduledCondition(ssn, job)
+			failTimeoutJob(ssn, job)
+		}
+
+		return result
+	}
+
 	ssn.AddJobValidFn(gp.Name(), validJobFn)
 
 	preemptableFn := func(preemptor *api.TaskInfo, preemptees []*api.TaskInfo) []*api.TaskInfo {
@@ -115,6 +316,11 @@ func (gp *gangPlugin) OnSessionOpen(ssn *framework.Session) {
 			// preemptable := job.MinAvailable <= readyTaskNum(job)-1 && preemptor.Priority > job.Priority
 			preemptable := job.MinAvailable <= readyTaskNum(job)-1 || job.MinAvailable == 1
 
+			// The minimal member of preemptee's role should be kept.
+			nums := readyTaskNumOfRoles(job)
+			nums[preemptee.Role]--
+			preemptable = preemptable && job.MinTaskMemberMet(nums)
+
 			if !preemptable {
 				glog.V(3).Infof("Can not preempt task <%v/%v> because of gang-scheduling",
 					preemptee.Namespace, preemptee.Name)
@@ -167,11 +373,26 @@ func (gp *gangPlugin) OnSessionClose(ssn *framework.Session) {
 	var unreadyTaskCount int32
 	var unScheduleJobCount int
 	for _, job := range ssn.Jobs {
+		updateScheduledCondition(ssn, job)
+
+		if failTimeoutJob(ssn, job) {
+			continue
+		}
+
+		// The InQueue job is not unschedulable before its pods are created.
+		if job.PodGroup != nil && job.PodGroup.Status.Phase == v1alpha1.PodGroupInQueue &&
+			validTaskNum(job) < job.MinAvailable {
+			continue
+		}
+
 		jc := &v1alpha1.PodGroupCondition{}
 		if jobReady(job) != api.Ready {
 			unreadyTaskCount = job.MinAvailable - readyTaskNum(job)
 			msg := fmt.Sprintf("%v/%v tasks in gang unschedulable: %v",
 				job.MinAvailable-readyTaskNum(job), len(job.Tasks), job.FitError())
+			if roles := unmetRoles(job, readyTaskNumOfRoles(job)); len(roles) != 0 {
+				msg = fmt.Sprintf("%s; roles not ready: %s", msg, roles)
+			}
 
 			unScheduleJobCount += 1
 			metrics.UpdateUnscheduleTaskCount(job.Name, int(unreadyTaskCount))
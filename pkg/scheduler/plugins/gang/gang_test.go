@@ -5,6 +5,12 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
 	"k8s.io/apimachinery/pkg/types"
 	"testing"
+	"time"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache/fake"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
 
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -42,6 +48,270 @@ func TestJobReady(t *testing.T) {
 	}
 }
 
+func TestFeasibleResource(t *testing.T) {
+	total := api.NewResource(buildResourceList("10", "10G"))
+
+	tests := []struct {
+		name         string
+		queue        *api.QueueInfo
+		minResources v1.ResourceList
+		feasible     bool
+	}{
+		{
+			name:         "fits in cluster",
+			minResources: buildResourceList("8", "8G"),
+			feasible:     true,
+		},
+		{
+			name:         "exceeds cluster",
+			minResources: buildResourceList("12", "8G"),
+			feasible:     false,
+		},
+		{
+			name: "exceeds queue capability",
+			queue: api.NewQueueInfo(&v1alpha1.Queue{
+				Spec: v1alpha1.QueueSpec{
+					Capability: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+				},
+			}),
+			minResources: buildResourceList("8", "8G"),
+			feasible:     false,
+		},
+	}
+
+	for i, test := range tests {
+		min := api.NewResource(test.minResources)
+		if actual := min.LessEqual(feasibleResource(test.queue, total)); actual != test.feasible {
+			t.Errorf("case %d (%s): expected: %v, got %v ", i, test.name, test.feasible, actual)
+		}
+	}
+}
+
+func TestScheduleTimeout(t *testing.T) {
+	now := time.Now()
+	timeout := int32(60)
+
+	tests := []struct {
+		name      string
+		timeout   *int32
+		created   time.Time
+		scheduled []v1alpha1.PodGroupCondition
+		expected  bool
+	}{
+		{
+			name:     "no timeout",
+			created:  now.Add(-time.Hour),
+			expected: false,
+		},
+		{
+			name:     "before deadline",
+			timeout:  &timeout,
+			created:  now.Add(-30 * time.Second),
+			expected: false,
+		},
+		{
+			name:     "after deadline",
+			timeout:  &timeout,
+			created:  now.Add(-90 * time.Second),
+			expected: true,
+		},
+		{
+			name:    "scheduled",
+			timeout: &timeout,
+			created: now.Add(-time.Hour),
+			scheduled: []v1alpha1.PodGroupCondition{{
+				Type:               v1alpha1.PodGroupScheduledType,
+				Status:             v1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(now.Add(-time.Hour)),
+			}},
+			expected: false,
+		},
+		{
+			name:    "unscheduled before deadline",
+			timeout: &timeout,
+			created: now.Add(-time.Hour),
+			scheduled: []v1alpha1.PodGroupCondition{{
+				Type:               v1alpha1.PodGroupScheduledType,
+				Status:             v1.ConditionFalse,
+				LastTransitionTime: metav1.NewTime(now.Add(-30 * time.Second)),
+			}},
+			expected: false,
+		},
+		{
+			name:    "unscheduled after deadline",
+			timeout: &timeout,
+			created: now.Add(-time.Hour),
+			scheduled: []v1alpha1.PodGroupCondition{{
+				Type:               v1alpha1.PodGroupScheduledType,
+				Status:             v1.ConditionFalse,
+				LastTransitionTime: metav1.NewTime(now.Add(-90 * time.Second)),
+			}},
+			expected: true,
+		},
+	}
+
+	for i, test := range tests {
+		job := api.NewJobInfo("job")
+		job.SetPodGroup(&v1alpha1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "pg",
+				CreationTimestamp: metav1.NewTime(test.created),
+			},
+			Spec: v1alpha1.PodGroupSpec{
+				ScheduleTimeoutSeconds: test.timeout,
+			},
+			Status: v1alpha1.PodGroupStatus{
+				Conditions: test.scheduled,
+			},
+		})
+
+		if actual := scheduleTimeout(job, now); actual != test.expected {
+			t.Errorf("case %d (%s): expected: %v, got %v ", i, test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestScheduleTimeoutOfRunningJob(t *testing.T) {
+	framework.RegisterPluginBuilder("gang", New)
+	defer framework.CleanupPluginBuilders()
+
+	timeout := int32(60)
+	pg := &v1alpha1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "pg1",
+			Namespace:         "c1",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+		},
+		Spec: v1alpha1.PodGroupSpec{
+			MinMember:              1,
+			Queue:                  fake.DefaultQueue,
+			ScheduleTimeoutSeconds: &timeout,
+		},
+		Status: v1alpha1.PodGroupStatus{Phase: v1alpha1.PodGroupRunning},
+	}
+	pod := buildPod("c1", "p1", "n1", v1.PodRunning, buildResourceList("1", "1G"), nil, nil)
+	pod.Annotations = map[string]string{v1alpha1.GroupNameAnnotationKey: "pg1"}
+
+	fc := fake.New(
+		&v1alpha1.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: fake.DefaultQueue},
+			Spec:       v1alpha1.QueueSpec{Weight: 1},
+		},
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+			Status: v1.NodeStatus{
+				Capacity:    buildResourceList("4", "4G"),
+				Allocatable: buildResourceList("4", "4G"),
+			},
+		},
+		pg, pod,
+	)
+	tiers := []conf.Tier{{Plugins: []conf.PluginOption{{Name: "gang"}}}}
+
+	// The job has been running for an hour.
+	framework.CloseSession(framework.OpenSession(fc, tiers))
+	scheduled := fc.PodGroups["c1/pg1"]
+	if c := scheduledCondition(&api.JobInfo{PodGroup: scheduled}); c == nil || c.Status != v1.ConditionTrue {
+		t.Fatalf("expected pg1 is scheduled, got %v", scheduled)
+	}
+
+	// Its task is recreated, and it's pending again.
+	newPod := pod.DeepCopy()
+	newPod.Spec.NodeName = ""
+	newPod.Status.Phase = v1.PodPending
+	if err := fc.Update(newPod); err != nil {
+		t.Fatalf("failed to update pod: %v", err)
+	}
+	scheduled.Status.Phase = v1alpha1.PodGroupPending
+	if err := fc.Update(scheduled); err != nil {
+		t.Fatalf("failed to update pod group: %v", err)
+	}
+	framework.CloseSession(framework.OpenSession(fc, tiers))
+
+	unscheduled := fc.PodGroups["c1/pg1"]
+	if unscheduled.Status.Phase == v1alpha1.PodGroupFailed {
+		t.Errorf("expected pg1 is not failed right after it's unscheduled")
+	}
+	if c := scheduledCondition(&api.JobInfo{PodGroup: unscheduled}); c == nil || c.Status != v1.ConditionFalse {
+		t.Errorf("expected pg1 is unscheduled, got %v", unscheduled)
+	}
+}
+
+func TestValidJob(t *testing.T) {
+	framework.RegisterPluginBuilder("gang", New)
+	defer framework.CleanupPluginBuilders()
+
+	buildPodGroup := func(name string, minMember int32, minResources v1.ResourceList) *v1alpha1.PodGroup {
+		return &v1alpha1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "c1"},
+			Spec: v1alpha1.PodGroupSpec{
+				MinMember:    minMember,
+				MinResources: &minResources,
+				Queue:        fake.DefaultQueue,
+			},
+			Status: v1alpha1.PodGroupStatus{Phase: v1alpha1.PodGroupRunning},
+		}
+	}
+	buildGroupPod := func(name, group string) *v1.Pod {
+		pod := buildPod("c1", name, "", v1.PodPending, buildResourceList("1", "1G"), nil, nil)
+		pod.Annotations = map[string]string{v1alpha1.GroupNameAnnotationKey: group}
+		return pod
+	}
+
+	// pg4 can never be satisfied, and has been pending over its timeout.
+	timeout := int32(60)
+	pg4 := buildPodGroup("pg4", 1, buildResourceList("8", "1G"))
+	pg4.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	pg4.Spec.ScheduleTimeoutSeconds = &timeout
+	pg4.Status.Phase = v1alpha1.PodGroupPending
+
+	fc := fake.New(
+		&v1alpha1.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: fake.DefaultQueue},
+			Spec:       v1alpha1.QueueSpec{Weight: 1},
+		},
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+			Status: v1.NodeStatus{
+				Capacity:    buildResourceList("4", "4G"),
+				Allocatable: buildResourceList("4", "4G"),
+			},
+		},
+		pg4, buildGroupPod("p4", "pg4"),
+		// pg1 is valid.
+		buildPodGroup("pg1", 1, buildResourceList("1", "1G")),
+		buildGroupPod("p1", "pg1"),
+		// pg2 has not enough tasks.
+		buildPodGroup("pg2", 2, buildResourceList("1", "1G")),
+		buildGroupPod("p2", "pg2"),
+		// The minimal resources of pg3 can never be satisfied.
+		buildPodGroup("pg3", 1, buildResourceList("8", "1G")),
+		buildGroupPod("p3", "pg3"),
+	)
+
+	ssn := framework.OpenSession(fc, []conf.Tier{{Plugins: []conf.PluginOption{{Name: "gang"}}}})
+	defer framework.CloseSession(ssn)
+
+	if len(ssn.Jobs) != 1 || ssn.Jobs["c1/pg1"] == nil {
+		t.Errorf("expected only pg1 in session, got %v", ssn.Jobs)
+	}
+
+	for name, reason := range map[string]string{
+		"c1/pg2": v1alpha1.NotEnoughPodsReason,
+		"c1/pg3": v1alpha1.InfeasibleResourcesReason,
+	} {
+		pg := fc.PodGroups[name]
+		if pg == nil || len(pg.Status.Conditions) != 1 || pg.Status.Conditions[0].Reason != reason {
+			t.Errorf("expected condition of <%s> with reason <%s>, got %v", name, reason, pg)
+		}
+	}
+
+	if pg := fc.PodGroups["c1/pg4"]; pg == nil || pg.Status.Phase != v1alpha1.PodGroupFailed ||
+		pg.Status.Conditions[0].Reason != v1alpha1.ScheduleTimeoutReason {
+		t.Errorf("expected pg4 is failed by schedule timeout, got %v", pg)
+	}
+}
+
 func buildPod(ns, n, nn string, p v1.PodPhase, req v1.ResourceList, owner []metav1.OwnerReference, labels map[string]string) *v1.Pod {
 	return &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
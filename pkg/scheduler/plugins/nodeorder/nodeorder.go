@@ -44,6 +44,14 @@ const (
 	BalancedResourceWeight = "balancedresource.weight"
 )
 
+// Arguments is the argument schema of nodeorder plugin.
+var Arguments = framework.ArgumentSchema{
+	NodeAffinityWeight:     framework.ArgumentInt,
+	PodAffinityWeight:      framework.ArgumentInt,
+	LeastRequestedWeight:   framework.ArgumentInt,
+	BalancedResourceWeight: framework.ArgumentInt,
+}
+
 type nodeOrderPlugin struct {
 	// Arguments given for the plugin
 	pluginArguments map[string]string
@@ -167,6 +175,11 @@ func (pp *nodeOrderPlugin) Name() string {
 	return "nodeorder"
 }
 
+// ConcurrencySafe returns true as priorities only read the session.
+func (pp *nodeOrderPlugin) ConcurrencySafe() bool {
+	return true
+}
+
 type priorityWeight struct {
 	leastReqWeight          int
 	nodeAffinityWeight      int
@@ -0,0 +1,95 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+// The custom plugins are loaded in the test of this package instead of framework
+// package, whose test binary has a different version of framework package than
+// the plugins.
+
+// buildCustomPlugin builds the custom plugin in testdata into dir as name.so; the
+// test is skipped if Go plugins can not be built without cgo.
+func buildCustomPlugin(t *testing.T, source, dir, name string) {
+	out, err := exec.Command("go", "env", "CGO_ENABLED").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "1" {
+		t.Skip("cgo is not available to build Go plugins")
+	}
+
+	// The plugin must be built with the same flags as the test to be loaded.
+	args := []string{"build", "-buildmode=plugin"}
+	if raceEnabled {
+		args = append(args, "-race")
+	}
+	path := filepath.Join(dir, name+".so")
+	args = append(args, "-o", path, "./testdata/"+source)
+	cmd := exec.Command("go", args...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to build plugin %s: %v\n%s", source, err, out)
+	}
+}
+
+func TestLoadCustomPlugins(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kube-batch-plugins")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	loaded := filepath.Join(dir, "loaded")
+	clashed := filepath.Join(dir, "clashed")
+	for _, d := range []string{loaded, clashed} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+	}
+
+	// The sample plugin is registered by its PluginName, not the name of file.
+	buildCustomPlugin(t, "sample", loaded, "custom")
+	// The plugin without PluginName is registered by the name of file.
+	buildCustomPlugin(t, "gang", clashed, "gang")
+
+	if err := framework.LoadCustomPlugins(loaded); err != nil {
+		t.Fatalf("failed to load custom plugins: %v", err)
+	}
+	defer framework.UnregisterPluginBuilder("sample")
+
+	builder, found := framework.GetPluginBuilder("sample")
+	if !found {
+		t.Fatalf("expected plugin <sample> is registered")
+	}
+	if name := builder(map[string]string{"sample.weight": "1"}).Name(); name != "sample" {
+		t.Errorf("expected plugin <sample>, got <%s>", name)
+	}
+	if _, found := framework.GetPluginArguments("sample"); !found {
+		t.Errorf("expected arguments of plugin <sample> are registered")
+	}
+
+	err = framework.LoadCustomPlugins(clashed)
+	if err == nil || !strings.Contains(err.Error(), "clashes") {
+		t.Errorf("expected plugin <gang> clashes with the built-in one, got %v", err)
+	}
+}
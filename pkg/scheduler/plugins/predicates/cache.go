@@ -0,0 +1,171 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicates
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	hashutil "k8s.io/kubernetes/pkg/util/hash"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+)
+
+// failure is the predicate failure of tasks on a node, which is nil if they
+// fit the node. It's cached for the equivalence class of tasks without any of
+// them, so the error of each task names the task itself.
+type failure func(task *api.TaskInfo) error
+
+// nodeFailure returns the failure whose message is format with the name of
+// node as %[1]s, and the namespace and name of task as %[2]s and %[3]s.
+func nodeFailure(format string, node string) failure {
+	return func(task *api.TaskInfo) error {
+		return fmt.Errorf(format, node, task.Namespace, task.Name)
+	}
+}
+
+// errFailure returns the failure of err, which does not name the task.
+func errFailure(err error) failure {
+	return func(*api.TaskInfo) error {
+		return err
+	}
+}
+
+// of returns the error of task by the failure, or nil if it fits.
+func (f failure) of(task *api.TaskInfo) error {
+	if f == nil {
+		return nil
+	}
+	return f(task)
+}
+
+// equivalenceCache remembers the predicate results of the equivalence classes
+// of tasks on each node in a session; the results of a node are dropped once
+// the tasks on it are changed.
+type equivalenceCache struct {
+	sync.RWMutex
+
+	// results are the predicate results by node name and equivalence hash.
+	results map[string]map[uint64]failure
+}
+
+func newEquivalenceCache() *equivalenceCache {
+	return &equivalenceCache{
+		results: map[string]map[uint64]failure{},
+	}
+}
+
+// lookup returns the predicate result of the equivalence class on node, and
+// whether it's found.
+func (ec *equivalenceCache) lookup(nodeName string, hash uint64) (failure, bool) {
+	ec.RLock()
+	defer ec.RUnlock()
+
+	f, found := ec.results[nodeName][hash]
+	return f, found
+}
+
+func (ec *equivalenceCache) update(nodeName string, hash uint64, f failure) {
+	ec.Lock()
+	defer ec.Unlock()
+
+	if _, found := ec.results[nodeName]; !found {
+		ec.results[nodeName] = map[uint64]failure{}
+	}
+	ec.results[nodeName][hash] = f
+}
+
+func (ec *equivalenceCache) invalidateNode(nodeName string) {
+	ec.Lock()
+	defer ec.Unlock()
+
+	delete(ec.results, nodeName)
+}
+
+func (ec *equivalenceCache) invalidateAll() {
+	ec.Lock()
+	defer ec.Unlock()
+
+	ec.results = map[string]map[uint64]failure{}
+}
+
+// podTemplate is the part of pod spec checked by predicates, which is used as
+// pod template hash if the pod is not labeled with one by its controller.
+type podTemplate struct {
+	Labels       map[string]string
+	NodeSelector map[string]string
+	Affinity     *v1.Affinity
+	Tolerations  []v1.Toleration
+	Ports        [][]v1.ContainerPort
+}
+
+// templateHash returns the pod template hash labeled by controller, or the
+// hash of the pod spec checked by predicates.
+func templateHash(pod *v1.Pod) string {
+	for _, key := range []string{appsv1.DefaultDeploymentUniqueLabelKey, appsv1.ControllerRevisionHashLabelKey} {
+		if hash, found := pod.Labels[key]; found {
+			return hash
+		}
+	}
+
+	template := &podTemplate{
+		Labels:       pod.Labels,
+		NodeSelector: pod.Spec.NodeSelector,
+		Affinity:     pod.Spec.Affinity,
+		Tolerations:  pod.Spec.Tolerations,
+	}
+	for _, c := range pod.Spec.Containers {
+		template.Ports = append(template.Ports, c.Ports)
+	}
+
+	hasher := fnv.New64a()
+	hashutil.DeepHashObject(hasher, template)
+	return string(hasher.Sum(nil))
+}
+
+// hasPodAffinity returns whether the pod has inter-pod affinity or
+// anti-affinity, whose predicate result depends on the pods of other nodes.
+func hasPodAffinity(pod *v1.Pod) bool {
+	affinity := pod.Spec.Affinity
+	return affinity != nil && (affinity.PodAffinity != nil || affinity.PodAntiAffinity != nil)
+}
+
+// equivalenceHash returns the hash of the equivalence class of task, and
+// whether it's cacheable: tasks of the same PodGroup, owner and pod template
+// hash get the same predicate results on a node. Tasks without controller or
+// with inter-pod affinity are not cacheable.
+func equivalenceHash(task *api.TaskInfo) (uint64, bool) {
+	pod := task.Pod
+	if pod == nil || hasPodAffinity(pod) {
+		return 0, false
+	}
+
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return 0, false
+	}
+
+	hasher := fnv.New64a()
+	hashutil.DeepHashObject(hasher, []string{
+		string(task.Job), string(owner.UID), templateHash(pod),
+	})
+	return hasher.Sum64(), true
+}
@@ -31,9 +31,15 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
 )
 
+// Arguments is the argument schema of predicates plugin, which takes no argument.
+var Arguments = framework.ArgumentSchema{}
+
 type predicatesPlugin struct {
 	// Arguments given for the plugin
 	pluginArguments map[string]string
+
+	// eCache is the equivalence cache of predicate results in session.
+	eCache *equivalenceCache
 }
 
 func New(arguments map[string]string) framework.Plugin {
@@ -44,6 +50,11 @@ func (pp *predicatesPlugin) Name() string {
 	return "predicates"
 }
 
+// ConcurrencySafe returns true as predicates only read the session.
+func (pp *predicatesPlugin) ConcurrencySafe() bool {
+	return true
+}
+
 type podLister struct {
 	session *framework.Session
 }
@@ -120,87 +131,118 @@ func (pp *predicatesPlugin) OnSessionOpen(ssn *framework.Session) {
 		session: ssn,
 	}
 
-	ssn.AddPredicateFn(pp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) error {
+	predicate := func(task *api.TaskInfo, node *api.NodeInfo) failure {
 		nodeInfo := cache.NewNodeInfo(node.Pods()...)
 		nodeInfo.SetNode(node.Node)
 
 		if node.Allocatable.MaxTaskNum <= len(nodeInfo.Pods()) {
-			return fmt.Errorf("node <%s> can not allow more task running on it", node.Name)
+			return errFailure(fmt.Errorf("node <%s> can not allow more task running on it", node.Name))
 		}
 
 		// NodeSelector Predicate
 		fit, _, err := predicates.PodMatchNodeSelector(task.Pod, nil, nodeInfo)
 		if err != nil {
-			return err
+			return errFailure(err)
 		}
 
 		glog.V(4).Infof("NodeSelect predicates Task <%s/%s> on Node <%s>: fit %t, err %v",
 			task.Namespace, task.Name, node.Name, fit, err)
 
 		if !fit {
-			return fmt.Errorf("node <%s> didn't match task <%s/%s> node selector",
-				node.Name, task.Namespace, task.Name)
+			return nodeFailure("node <%[1]s> didn't match task <%[2]s/%[3]s> node selector", node.Name)
 		}
 
 		// HostPorts Predicate
 		fit, _, err = predicates.PodFitsHostPorts(task.Pod, nil, nodeInfo)
 		if err != nil {
-			return err
+			return errFailure(err)
 		}
 
 		glog.V(4).Infof("HostPorts predicates Task <%s/%s> on Node <%s>: fit %t, err %v",
 			task.Namespace, task.Name, node.Name, fit, err)
 
 		if !fit {
-			return fmt.Errorf("node <%s> didn't have available host ports for task <%s/%s>",
-				node.Name, task.Namespace, task.Name)
+			return nodeFailure("node <%[1]s> didn't have available host ports for task <%[2]s/%[3]s>", node.Name)
 		}
 
 		// Check to see if node.Spec.Unschedulable is set
 		fit, _, err = CheckNodeUnschedulable(task.Pod, nodeInfo)
 		if err != nil {
-			return err
+			return errFailure(err)
 		}
 
 		glog.V(4).Infof("Check Unschedulable Task <%s/%s> on Node <%s>: fit %t, err %v",
 			task.Namespace, task.Name, node.Name, fit, err)
 
 		if !fit {
-			return fmt.Errorf("task <%s/%s> node <%s> set to unschedulable",
-				task.Namespace, task.Name, node.Name)
+			return nodeFailure("task <%[2]s/%[3]s> node <%[1]s> set to unschedulable", node.Name)
 		}
 
 		// Toleration/Taint Predicate
 		fit, _, err = predicates.PodToleratesNodeTaints(task.Pod, nil, nodeInfo)
 		if err != nil {
-			return err
+			return errFailure(err)
 		}
 
 		glog.V(4).Infof("Toleration/Taint predicates Task <%s/%s> on Node <%s>: fit %t, err %v",
 			task.Namespace, task.Name, node.Name, fit, err)
 
 		if !fit {
-			return fmt.Errorf("task <%s/%s> does not tolerate node <%s> taints",
-				task.Namespace, task.Name, node.Name)
+			return nodeFailure("task <%[2]s/%[3]s> does not tolerate node <%[1]s> taints", node.Name)
 		}
 
 		// Pod Affinity/Anti-Affinity Predicate
 		podAffinityPredicate := predicates.NewPodAffinityPredicate(ni, pl)
 		fit, _, err = podAffinityPredicate(task.Pod, nil, nodeInfo)
 		if err != nil {
-			return err
+			return errFailure(err)
 		}
 
 		glog.V(4).Infof("Pod Affinity/Anti-Affinity predicates Task <%s/%s> on Node <%s>: fit %t, err %v",
 			task.Namespace, task.Name, node.Name, fit, err)
 
 		if !fit {
-			return fmt.Errorf("task <%s/%s> affinity/anti-affinity failed on node <%s>",
-				node.Name, task.Namespace, task.Name)
+			return nodeFailure("task <%[2]s/%[3]s> affinity/anti-affinity failed on node <%[1]s>", node.Name)
 		}
 
 		return nil
+	}
+
+	pp.eCache = newEquivalenceCache()
+
+	ssn.AddPredicateFn(pp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) error {
+		hash, cacheable := equivalenceHash(task)
+		if !cacheable {
+			return predicate(task, node).of(task)
+		}
+
+		if f, found := pp.eCache.lookup(node.Name, hash); found {
+			err := f.of(task)
+			glog.V(4).Infof("Predicates of Task <%s/%s> on Node <%s> are found in equivalence cache: %v",
+				task.Namespace, task.Name, node.Name, err)
+			return err
+		}
+
+		f := predicate(task, node)
+		pp.eCache.update(node.Name, hash, f)
+		return f.of(task)
+	})
+
+	// The predicates of a node depend on the tasks on it; the predicates of
+	// all nodes depend on the tasks with inter-pod affinity.
+	invalidate := func(event *framework.Event) {
+		if hasPodAffinity(event.Task.Pod) {
+			pp.eCache.invalidateAll()
+		} else {
+			pp.eCache.invalidateNode(event.Task.NodeName)
+		}
+	}
+	ssn.AddEventHandler(&framework.EventHandler{
+		AllocateFunc:   invalidate,
+		DeallocateFunc: invalidate,
 	})
 }
 
-func (pp *predicatesPlugin) OnSessionClose(ssn *framework.Session) {}
+func (pp *predicatesPlugin) OnSessionClose(ssn *framework.Session) {
+	pp.eCache = nil
+}
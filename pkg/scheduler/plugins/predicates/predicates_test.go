@@ -0,0 +1,266 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package predicates
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+func buildPod(ns, n, groupName, owner string, labels map[string]string) *v1.Pod {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       types.UID(fmt.Sprintf("%v-%v", ns, n)),
+			Name:      n,
+			Namespace: ns,
+			Labels:    labels,
+			Annotations: map[string]string{
+				kbv1.GroupNameAnnotationKey: groupName,
+			},
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodPending,
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse("1"),
+							v1.ResourceMemory: resource.MustParse("1G"),
+						},
+					},
+				},
+			},
+			Priority: new(int32),
+		},
+	}
+
+	if len(owner) != 0 {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{
+			{
+				APIVersion: "batch/v1",
+				Kind:       "Job",
+				Name:       owner,
+				UID:        types.UID(owner),
+				Controller: &controller,
+			},
+		}
+	}
+
+	return pod
+}
+
+type fakeStatusUpdater struct {
+}
+
+func (ftsu *fakeStatusUpdater) UpdatePodCondition(pod *v1.Pod, podCondition *v1.PodCondition) (*v1.Pod, error) {
+	// do nothing here
+	return pod, nil
+}
+
+func (ftsu *fakeStatusUpdater) UpdatePodGroup(pg *kbv1.PodGroup) (*kbv1.PodGroup, error) {
+	// do nothing here
+	return pg, nil
+}
+
+func (ftsu *fakeStatusUpdater) UpdateQueueStatus(queue *kbv1.Queue) (*kbv1.Queue, error) {
+	// do nothing here
+	return queue, nil
+}
+
+func TestEquivalenceHash(t *testing.T) {
+	hashOf := func(pod *v1.Pod, job string) (uint64, bool) {
+		return equivalenceHash(&api.TaskInfo{Job: api.JobID(job), Pod: pod})
+	}
+
+	base, cacheable := hashOf(buildPod("c1", "p1", "pg1", "o1", map[string]string{"app": "a"}), "c1/pg1")
+	if !cacheable {
+		t.Fatalf("expected task with controller to be cacheable")
+	}
+
+	affinityPod := buildPod("c1", "p2", "pg1", "o1", map[string]string{"app": "a"})
+	affinityPod.Spec.Affinity = &v1.Affinity{PodAntiAffinity: &v1.PodAntiAffinity{}}
+
+	tests := []struct {
+		name      string
+		pod       *v1.Pod
+		job       string
+		equal     bool
+		cacheable bool
+	}{
+		{
+			name:      "same template of same owner and PodGroup",
+			pod:       buildPod("c1", "p2", "pg1", "o1", map[string]string{"app": "a"}),
+			job:       "c1/pg1",
+			equal:     true,
+			cacheable: true,
+		},
+		{
+			name:      "different template",
+			pod:       buildPod("c1", "p2", "pg1", "o1", map[string]string{"app": "b"}),
+			job:       "c1/pg1",
+			cacheable: true,
+		},
+		{
+			name:      "different owner",
+			pod:       buildPod("c1", "p2", "pg1", "o2", map[string]string{"app": "a"}),
+			job:       "c1/pg1",
+			cacheable: true,
+		},
+		{
+			name:      "different PodGroup",
+			pod:       buildPod("c1", "p2", "pg2", "o1", map[string]string{"app": "a"}),
+			job:       "c1/pg2",
+			cacheable: true,
+		},
+		{
+			name: "no controller",
+			pod:  buildPod("c1", "p2", "pg1", "", map[string]string{"app": "a"}),
+			job:  "c1/pg1",
+		},
+		{
+			name: "inter-pod affinity",
+			pod:  affinityPod,
+			job:  "c1/pg1",
+		},
+	}
+
+	for _, test := range tests {
+		hash, cacheable := hashOf(test.pod, test.job)
+		if cacheable != test.cacheable {
+			t.Errorf("case %s: expected cacheable %v, got %v", test.name, test.cacheable, cacheable)
+			continue
+		}
+		if cacheable && (hash == base) != test.equal {
+			t.Errorf("case %s: expected equal hash %v, got %v", test.name, test.equal, hash == base)
+		}
+	}
+
+	// The pod template hash labeled by controller is used if any.
+	hash1, _ := hashOf(buildPod("c1", "p1", "pg1", "o1", map[string]string{"pod-template-hash": "h1", "index": "1"}), "c1/pg1")
+	hash2, _ := hashOf(buildPod("c1", "p2", "pg1", "o1", map[string]string{"pod-template-hash": "h1", "index": "2"}), "c1/pg1")
+	if hash1 != hash2 {
+		t.Errorf("expected equal hash of the same pod template hash")
+	}
+}
+
+func TestEquivalenceCache(t *testing.T) {
+	pp := New(nil).(*predicatesPlugin)
+	framework.RegisterPluginBuilder(pp.Name(), func(map[string]string) framework.Plugin { return pp })
+	defer framework.CleanupPluginBuilders()
+
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Queues:        make(map[api.QueueID]*api.QueueInfo),
+		StatusUpdater: &fakeStatusUpdater{},
+		Recorder:      record.NewFakeRecorder(100),
+	}
+	// Node n1 allows only one task.
+	alloc := v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse("4"),
+		v1.ResourceMemory: resource.MustParse("8G"),
+		v1.ResourcePods:   resource.MustParse("1"),
+	}
+	schedulerCache.AddNode(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+		Status:     v1.NodeStatus{Capacity: alloc, Allocatable: alloc},
+	})
+	// Node n2 is unschedulable.
+	schedulerCache.AddNode(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "n2"},
+		Spec:       v1.NodeSpec{Unschedulable: true},
+		Status:     v1.NodeStatus{Capacity: alloc, Allocatable: alloc},
+	})
+	schedulerCache.AddPod(buildPod("c1", "p1", "pg1", "o1", nil))
+	schedulerCache.AddPod(buildPod("c1", "p2", "pg1", "o1", nil))
+	schedulerCache.AddPodGroup(&kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pg1",
+			Namespace: "c1",
+		},
+		Spec: kbv1.PodGroupSpec{
+			Queue: "q1",
+		},
+	})
+	schedulerCache.AddQueue(&kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "q1",
+		},
+	})
+
+	ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name: pp.Name(),
+				},
+			},
+		},
+	})
+	defer framework.CloseSession(ssn)
+
+	job := ssn.Jobs["c1/pg1"]
+	p1 := job.Tasks["c1-p1"]
+	p2 := job.Tasks["c1-p2"]
+	n1 := ssn.Nodes["n1"]
+	n2 := ssn.Nodes["n2"]
+
+	if err := ssn.PredicateFn(p1, n1); err != nil {
+		t.Fatalf("expected p1 fits n1, got %v", err)
+	}
+	if len(pp.eCache.results["n1"]) != 1 {
+		t.Errorf("expected the result of p1 on n1 is cached, got %v", pp.eCache.results)
+	}
+	if err := ssn.PredicateFn(p2, n1); err != nil {
+		t.Errorf("expected p2 fits n1, got %v", err)
+	}
+
+	// The cached failure names each task of the class.
+	for _, task := range []*api.TaskInfo{p1, p2} {
+		expected := fmt.Sprintf("task <c1/%s> node <n2> set to unschedulable", task.Name)
+		if err := ssn.PredicateFn(task, n2); err == nil || err.Error() != expected {
+			t.Errorf("expected failure <%s>, got %v", expected, err)
+		}
+	}
+	if len(pp.eCache.results["n2"]) != 1 {
+		t.Errorf("expected the failure on n2 is cached, got %v", pp.eCache.results)
+	}
+
+	// The result of n1 is dropped once p1 is placed on it.
+	ssn.Statement().Pipeline(p1, "n1")
+	if _, found := pp.eCache.results["n1"]; found {
+		t.Errorf("expected the results of n1 are invalidated, got %v", pp.eCache.results)
+	}
+	if err := ssn.PredicateFn(p2, n1); err == nil {
+		t.Errorf("expected p2 does not fit n1 after p1 is pipelined")
+	}
+}
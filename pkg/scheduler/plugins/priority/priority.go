@@ -22,6 +22,9 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
 )
 
+// Arguments is the argument schema of priority plugin, which takes no argument.
+var Arguments = framework.ArgumentSchema{}
+
 type priorityPlugin struct {
 	// Arguments given for the plugin
 	pluginArguments map[string]string
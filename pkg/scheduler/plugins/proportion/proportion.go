@@ -17,16 +17,34 @@ limitations under the License.
 package proportion
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/golang/glog"
 
+	"k8s.io/api/core/v1"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api/helpers"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
 )
 
+const (
+	// Hierarchical is the key for enabling hierarchical queues in YAML
+	Hierarchical = "proportion.hierarchical"
+)
+
+// Arguments is the argument schema of proportion plugin.
+var Arguments = framework.ArgumentSchema{
+	Hierarchical: framework.ArgumentBool,
+}
+
 type proportionPlugin struct {
 	totalResource *api.Resource
 	queueOpts     map[api.QueueID]*queueAttr
+	// hierarchical is whether to share resources through the queue tree.
+	hierarchical bool
 	// Arguments given for the plugin
 	pluginArguments map[string]string
 }
@@ -40,6 +58,21 @@ type queueAttr struct {
 	deserved  *api.Resource
 	allocated *api.Resource
 	request   *api.Resource
+
+	// capability is the upper limit of the queue's resources, nil means unlimited.
+	capability *api.Resource
+	// capped are the resources listed in the queue's capability, which are
+	// the only ones limited by it.
+	capped []v1.ResourceName
+	// guarantee is the resources reserved for the queue.
+	guarantee *api.Resource
+
+	// The following fields are only used in hierarchical mode.
+	parent   api.QueueID
+	children []*queueAttr
+	// ownRequest is the resources requested by the jobs of the queue itself,
+	// excluding the ones of its descendants.
+	ownRequest *api.Resource
 }
 
 func New(arguments map[string]string) framework.Plugin {
@@ -54,6 +87,12 @@ func (pp *proportionPlugin) Name() string {
 	return "proportion"
 }
 
+// ConcurrencySafe returns true as the predicate only reads the allocated
+// resources of queues, which are not changed while predicating nodes.
+func (pp *proportionPlugin) ConcurrencySafe() bool {
+	return true
+}
+
 func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 	// Prepare scheduling data for this session.
 	for _, n := range ssn.Nodes {
@@ -62,94 +101,117 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 
 	glog.V(4).Infof("The total resource is <%v>", pp.totalResource)
 
-	// Build attributes for Queues.
+	if args := pp.pluginArguments[Hierarchical]; args != "" {
+		hierarchical, err := strconv.ParseBool(args)
+		if err != nil {
+			glog.Warningf("Not able to parse %v because of error: %v", Hierarchical, err)
+		} else {
+			pp.hierarchical = hierarchical
+		}
+	}
+
+	// Build attributes for Queues; all Queues are considered in hierarchical mode,
+	// as the parent of a Queue may have no jobs.
+	if pp.hierarchical {
+		for _, queue := range ssn.Queues {
+			pp.queueOpts[queue.UID] = pp.newQueueAttr(queue)
+		}
+		pp.buildQueueTree()
+	}
+
 	for _, job := range ssn.Jobs {
 		glog.V(4).Infof("Considering Job <%s/%s>.", job.Namespace, job.Name)
 
 		if _, found := pp.queueOpts[job.Queue]; !found {
 			queue := ssn.Queues[job.Queue]
-			attr := &queueAttr{
-				queueID: queue.UID,
-				name:    queue.Name,
-				weight:  queue.Weight,
-
-				deserved:  api.EmptyResource(),
-				allocated: api.EmptyResource(),
-				request:   api.EmptyResource(),
-			}
-			pp.queueOpts[job.Queue] = attr
+			pp.queueOpts[job.Queue] = pp.newQueueAttr(queue)
 			glog.V(4).Infof("Added Queue <%s> attributes.", job.Queue)
 		}
 
 		for status, tasks := range job.TaskStatusIndex {
 			if api.AllocatedStatus(status) {
 				for _, t := range tasks {
-					attr := pp.queueOpts[job.Queue]
-					attr.allocated.Add(t.Resreq)
-					attr.request.Add(t.Resreq)
+					for _, attr := range pp.path(job.Queue) {
+						attr.allocated.Add(t.Resreq)
+						attr.request.Add(t.Resreq)
+					}
+					pp.queueOpts[job.Queue].ownRequest.Add(t.Resreq)
 				}
 			} else if status == api.Pending {
 				for _, t := range tasks {
-					attr := pp.queueOpts[job.Queue]
-					attr.request.Add(t.Resreq)
+					for _, attr := range pp.path(job.Queue) {
+						attr.request.Add(t.Resreq)
+					}
+					pp.queueOpts[job.Queue].ownRequest.Add(t.Resreq)
 				}
 			}
 		}
+
+		// The job requests its minimal resources at least, even though its pods
+		// are not created yet.
+		if job.MinResources != nil {
+			lack := helpers.Max(job.MinResources.Clone().Sub(job.TotalRequest), api.EmptyResource())
+			for _, attr := range pp.path(job.Queue) {
+				attr.request.Add(lack)
+			}
+			pp.queueOpts[job.Queue].ownRequest.Add(lack)
+		}
 	}
 
-	remaining := pp.totalResource.Clone()
-	meet := map[api.QueueID]struct{}{}
-	for {
-		totalWeight := int32(0)
+	if pp.hierarchical {
+		// Calculates the deserved of Queues top-down through the queue tree.
+		var attrs []*queueAttr
 		for _, attr := range pp.queueOpts {
-			if _, found := meet[attr.queueID]; found {
-				continue
+			if attr.parent == "" {
+				attrs = append(attrs, attr)
 			}
-			totalWeight += attr.weight
 		}
+		pp.divide(pp.totalResource.Clone(), attrs)
 
-		// If no queues, break
-		if totalWeight == 0 {
-			break
-		}
+		for len(attrs) != 0 {
+			attr := attrs[0]
+			attrs = attrs[1:]
 
-		// Calculates the deserved of each Queue.
-		deserved := api.EmptyResource()
-		for _, attr := range pp.queueOpts {
-			glog.V(4).Infof("Considering Queue <%s>: weight <%d>, total weight <%d>.",
-				attr.name, attr.weight, totalWeight)
-			if _, found := meet[attr.queueID]; found {
+			if len(attr.children) == 0 {
 				continue
 			}
 
-			attr.deserved.Add(remaining.Clone().Multi(float64(attr.weight) / float64(totalWeight)))
-			if !attr.deserved.LessEqual(attr.request) {
-				attr.deserved = helpers.Min(attr.deserved, attr.request)
-				meet[attr.queueID] = struct{}{}
-			}
-			pp.updateShare(attr)
-
-			glog.V(4).Infof("The attributes of queue <%s> in proportion: deserved <%v>, allocate <%v>, request <%v>, share <%0.2f>",
-				attr.name, attr.deserved, attr.allocated, attr.request, attr.share)
+			// The jobs of the Queue itself are funded before its children.
+			own := helpers.Min(attr.ownRequest, attr.deserved)
+			pp.divide(attr.deserved.Clone().Sub(own), attr.children)
 
-			deserved.Add(attr.deserved)
+			attrs = append(attrs, attr.children...)
 		}
-
-		remaining.Sub(deserved)
-		if remaining.IsEmpty() {
-			break
+	} else {
+		var attrs []*queueAttr
+		for _, attr := range pp.queueOpts {
+			attrs = append(attrs, attr)
 		}
+		pp.divide(pp.totalResource.Clone(), attrs)
 	}
 
 	ssn.AddQueueOrderFn(pp.Name(), func(l, r interface{}) int {
-		lv := l.(*api.QueueInfo)
-		rv := r.(*api.QueueInfo)
+		lv := pp.queueOpts[l.(*api.QueueInfo).UID]
+		rv := pp.queueOpts[r.(*api.QueueInfo).UID]
+
+		// In hierarchical mode, compares the shares of the ancestors which
+		// are siblings in the queue tree.
+		if pp.hierarchical {
+			lp, rp := pp.path(lv.queueID), pp.path(rv.queueID)
+			i := 0
+			for i < len(lp) && i < len(rp) && lp[i] == rp[i] {
+				i++
+			}
+			if i < len(lp) && i < len(rp) {
+				lv, rv = lp[i], rp[i]
+			}
+		}
 
-		if pp.queueOpts[lv.UID].share == pp.queueOpts[rv.UID].share {
+		if lv.share == rv.share {
 			return 0
 		}
 
-		if pp.queueOpts[lv.UID].share < pp.queueOpts[rv.UID].share {
+		if lv.share < rv.share {
 			return -1
 		}
 
@@ -160,22 +222,46 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 		var victims []*api.TaskInfo
 		allocations := map[api.QueueID]*api.Resource{}
 
+		reclaimerPath := pp.path(ssn.Jobs[reclaimer.Job].Queue)
+
 		for _, reclaimee := range reclaimees {
 			job := ssn.Jobs[reclaimee.Job]
-			attr := pp.queueOpts[job.Queue]
 
-			if _, found := allocations[job.Queue]; !found {
-				allocations[job.Queue] = attr.allocated.Clone()
+			// Only the Queues which are not the ancestors of reclaimer's Queue
+			// give up resources; it's the reclaimee's Queue only in flat mode.
+			path := pp.path(job.Queue)
+			if pp.hierarchical {
+				i := 0
+				for i < len(path) && i < len(reclaimerPath) && path[i] == reclaimerPath[i] {
+					i++
+				}
+				path = path[i:]
 			}
-			allocated := allocations[job.Queue]
-			if allocated.Less(reclaimee.Resreq) {
-				glog.Errorf("Failed to allocate resource for Task <%s/%s> in Queue <%s>， not enough resource.",
-					reclaimee.Namespace, reclaimee.Name, job.Queue)
+			if len(path) == 0 {
 				continue
 			}
 
-			allocated.Sub(reclaimee.Resreq)
-			if attr.deserved.LessEqual(allocated) {
+			reclaimable := true
+			for _, attr := range path {
+				if _, found := allocations[attr.queueID]; !found {
+					allocations[attr.queueID] = attr.allocated.Clone()
+				}
+				allocated := allocations[attr.queueID]
+				if allocated.Less(reclaimee.Resreq) {
+					glog.Errorf("Failed to allocate resource for Task <%s/%s> in Queue <%s>， not enough resource.",
+						reclaimee.Namespace, reclaimee.Name, attr.name)
+					reclaimable = false
+					break
+				}
+
+				allocated.Sub(reclaimee.Resreq)
+				// Do not reclaim the queue below its deserved or guaranteed resources.
+				if !attr.deserved.LessEqual(allocated) || !attr.guarantee.LessEqual(allocated) {
+					reclaimable = false
+				}
+			}
+
+			if reclaimable {
 				victims = append(victims, reclaimee)
 			}
 		}
@@ -185,47 +271,313 @@ func (pp *proportionPlugin) OnSessionOpen(ssn *framework.Session) {
 
 	ssn.AddOverusedFn(pp.Name(), func(obj interface{}) bool {
 		queue := obj.(*api.QueueInfo)
-		attr := pp.queueOpts[queue.UID]
 
-		overused := attr.deserved.LessEqual(attr.allocated)
-		if overused {
-			glog.V(3).Infof("Queue <%v>: deserved <%v>, allocated <%v>, share <%v>",
-				queue.Name, attr.deserved, attr.allocated, attr.share)
+		// The Queue is overused if any of its ancestors is overused.
+		for _, attr := range pp.path(queue.UID) {
+			overused := attr.deserved.LessEqual(attr.allocated) || attr.reachCapability()
+			if overused {
+				glog.V(3).Infof("Queue <%v>: deserved <%v>, allocated <%v>, capability <%v>, share <%v>",
+					attr.name, attr.deserved, attr.allocated, attr.capability, attr.share)
+				return true
+			}
 		}
 
-		return overused
+		return false
+	})
+
+	// The capability is a hard limit: the task is not placed if it makes its
+	// Queue, or any of the ancestors, exceed the capability.
+	ssn.AddPredicateFn(pp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) error {
+		job, found := ssn.Jobs[task.Job]
+		if !found {
+			return nil
+		}
+		for _, attr := range pp.path(job.Queue) {
+			if !attr.fitCapability(task.Resreq) {
+				return fmt.Errorf("task <%s/%s> exceeds capability <%v> of queue <%s>, allocated <%v>",
+					task.Namespace, task.Name, attr.capability, attr.name, attr.allocated)
+			}
+		}
+		return nil
+	})
+
+	ssn.AddJobEnqueueableFn(pp.Name(), func(obj interface{}) bool {
+		job := obj.(*api.JobInfo)
+		if job.MinResources == nil {
+			return true
+		}
+
+		// The resources reserved for the InQueue jobs, which are not allocated yet.
+		reserved := map[api.QueueID]*api.Resource{}
+		for _, j := range ssn.Jobs {
+			if j.MinResources == nil || j.PodGroup == nil ||
+				j.PodGroup.Status.Phase != v1alpha1.PodGroupInQueue {
+				continue
+			}
+			unallocated := helpers.Max(j.MinResources.Clone().Sub(j.Allocated), api.EmptyResource())
+			for _, attr := range pp.path(j.Queue) {
+				if _, found := reserved[attr.queueID]; !found {
+					reserved[attr.queueID] = api.EmptyResource()
+				}
+				reserved[attr.queueID].Add(unallocated)
+			}
+		}
+
+		// The job is enqueueable if the remaining deserved resources of its Queue,
+		// and of the ancestors in hierarchical mode, cover its minimal resources.
+		for _, attr := range pp.path(job.Queue) {
+			remaining := attr.deserved.Clone().Sub(attr.allocated)
+			if r, found := reserved[attr.queueID]; found {
+				remaining.Sub(r)
+			}
+			remaining = helpers.Max(remaining, api.EmptyResource())
+			if !job.MinResources.LessEqual(remaining) {
+				glog.V(3).Infof("Queue <%s>: remaining <%v> can not cover min resources <%v> of job <%s/%s>",
+					attr.name, remaining, job.MinResources, job.Namespace, job.Name)
+				return false
+			}
+		}
+
+		return true
 	})
 
 	// Register event handlers.
 	ssn.AddEventHandler(&framework.EventHandler{
 		AllocateFunc: func(event *framework.Event) {
 			job := ssn.Jobs[event.Task.Job]
-			attr := pp.queueOpts[job.Queue]
-			attr.allocated.Add(event.Task.Resreq)
+			for _, attr := range pp.path(job.Queue) {
+				attr.allocated.Add(event.Task.Resreq)
 
-			pp.updateShare(attr)
+				pp.updateShare(attr)
 
-			glog.V(4).Infof("Proportion AllocateFunc: task <%v/%v>, resreq <%v>,  share <%v>",
-				event.Task.Namespace, event.Task.Name, event.Task.Resreq, attr.share)
+				glog.V(4).Infof("Proportion AllocateFunc: task <%v/%v>, resreq <%v>, queue <%v>, share <%v>",
+					event.Task.Namespace, event.Task.Name, event.Task.Resreq, attr.name, attr.share)
+			}
 		},
 		DeallocateFunc: func(event *framework.Event) {
 			job := ssn.Jobs[event.Task.Job]
-			attr := pp.queueOpts[job.Queue]
-			attr.allocated.Sub(event.Task.Resreq)
+			for _, attr := range pp.path(job.Queue) {
+				attr.allocated.Sub(event.Task.Resreq)
 
-			pp.updateShare(attr)
+				pp.updateShare(attr)
 
-			glog.V(4).Infof("Proportion EvictFunc: task <%v/%v>, resreq <%v>,  share <%v>",
-				event.Task.Namespace, event.Task.Name, event.Task.Resreq, attr.share)
+				glog.V(4).Infof("Proportion EvictFunc: task <%v/%v>, resreq <%v>, queue <%v>, share <%v>",
+					event.Task.Namespace, event.Task.Name, event.Task.Resreq, attr.name, attr.share)
+			}
 		},
 	})
 }
 
 func (pp *proportionPlugin) OnSessionClose(ssn *framework.Session) {
+	// Record the accounting of queues into their status, which will be
+	// updated by the framework when closing session.
+	for _, attr := range pp.queueOpts {
+		queue, found := ssn.Queues[attr.queueID]
+		if !found {
+			continue
+		}
+		queue.Queue.Status.Deserved = attr.deserved.ResourceList()
+		queue.Queue.Status.Allocated = attr.allocated.ResourceList()
+		queue.Queue.Status.Request = attr.request.ResourceList()
+	}
+
 	pp.totalResource = nil
 	pp.queueOpts = nil
 }
 
+func (pp *proportionPlugin) newQueueAttr(queue *api.QueueInfo) *queueAttr {
+	return &queueAttr{
+		queueID: queue.UID,
+		name:    queue.Name,
+		weight:  queue.Weight,
+
+		deserved:  api.EmptyResource(),
+		allocated: api.EmptyResource(),
+		request:   api.EmptyResource(),
+
+		capability: pp.queueCapability(queue),
+		capped:     cappedResources(queue),
+		guarantee:  api.NewResource(queue.Queue.Spec.Guarantee),
+
+		parent:     api.QueueID(queue.Queue.Spec.Parent),
+		ownRequest: api.EmptyResource(),
+	}
+}
+
+// buildQueueTree links the Queues by their parent; the Queue whose parent
+// does not exist, or which is in a cycle, is taken as a root of the tree.
+func (pp *proportionPlugin) buildQueueTree() {
+	for _, attr := range pp.queueOpts {
+		if _, found := pp.queueOpts[attr.parent]; attr.parent != "" && !found {
+			glog.Warningf("The parent <%s> of Queue <%s> does not exist, take it as root.",
+				attr.parent, attr.name)
+			attr.parent = ""
+		}
+	}
+
+	for _, attr := range pp.queueOpts {
+		visited := map[api.QueueID]bool{attr.queueID: true}
+		for p := attr.parent; p != ""; p = pp.queueOpts[p].parent {
+			if visited[p] {
+				glog.Warningf("Queue <%s> is in a cycle of the queue tree, take it as root.",
+					attr.name)
+				attr.parent = ""
+				break
+			}
+			visited[p] = true
+		}
+	}
+
+	for _, attr := range pp.queueOpts {
+		if attr.parent != "" {
+			parent := pp.queueOpts[attr.parent]
+			parent.children = append(parent.children, attr)
+		}
+	}
+}
+
+// path returns the attributes of the Queue and its ancestors, from the root of
+// the queue tree to the Queue; it's the Queue only in flat mode.
+func (pp *proportionPlugin) path(queue api.QueueID) []*queueAttr {
+	attr := pp.queueOpts[queue]
+	if !pp.hierarchical {
+		return []*queueAttr{attr}
+	}
+
+	var path []*queueAttr
+	for ; attr != nil; attr = pp.queueOpts[attr.parent] {
+		path = append([]*queueAttr{attr}, path...)
+	}
+
+	return path
+}
+
+// divide shares the resources among the Queues: the guarantee of each Queue is
+// funded at first, and then the rest is shared by weight.
+func (pp *proportionPlugin) divide(total *api.Resource, attrs []*queueAttr) {
+	// Fund the guarantee of each Queue before sharing the rest by weight.
+	guaranteed := api.EmptyResource()
+	for _, attr := range attrs {
+		attr.deserved = helpers.Min(attr.guarantee, attr.request)
+		if attr.capability != nil {
+			attr.deserved = helpers.Min(attr.deserved, attr.capability)
+		}
+		guaranteed.Add(attr.deserved)
+	}
+
+	if !guaranteed.LessEqual(total) {
+		glog.Warningf("The guaranteed resource <%v> exceeds the total resource <%v>",
+			guaranteed, total)
+	}
+
+	remaining := helpers.Max(total.Clone().Sub(guaranteed), api.EmptyResource())
+	meet := map[api.QueueID]struct{}{}
+	for {
+		totalWeight := int32(0)
+		for _, attr := range attrs {
+			if _, found := meet[attr.queueID]; found {
+				continue
+			}
+			totalWeight += attr.weight
+		}
+
+		// If no queues, break
+		if totalWeight == 0 {
+			break
+		}
+
+		// Calculates the deserved of each Queue.
+		increased := api.EmptyResource()
+		decreased := api.EmptyResource()
+		for _, attr := range attrs {
+			glog.V(4).Infof("Considering Queue <%s>: weight <%d>, total weight <%d>.",
+				attr.name, attr.weight, totalWeight)
+			if _, found := meet[attr.queueID]; found {
+				continue
+			}
+
+			oldDeserved := attr.deserved.Clone()
+			attr.deserved.Add(remaining.Clone().Multi(float64(attr.weight) / float64(totalWeight)))
+			if attr.capability != nil && !attr.deserved.LessEqual(attr.capability) {
+				attr.deserved = helpers.Min(attr.deserved, attr.capability)
+				meet[attr.queueID] = struct{}{}
+			}
+			if !attr.deserved.LessEqual(attr.request) {
+				attr.deserved = helpers.Min(attr.deserved, attr.request)
+				meet[attr.queueID] = struct{}{}
+			}
+			pp.updateShare(attr)
+
+			glog.V(4).Infof("The attributes of queue <%s> in proportion: deserved <%v>, allocate <%v>, request <%v>, share <%0.2f>",
+				attr.name, attr.deserved, attr.allocated, attr.request, attr.share)
+
+			increased.Add(attr.deserved)
+			decreased.Add(oldDeserved)
+		}
+
+		remaining.Sub(increased).Add(decreased)
+		if remaining.IsEmpty() || increased.Equal(decreased) {
+			break
+		}
+	}
+}
+
+// cappedResources returns the resources listed in the capability of queue.
+func cappedResources(queue *api.QueueInfo) []v1.ResourceName {
+	var names []v1.ResourceName
+	for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory, api.GPUResourceName} {
+		if _, found := queue.Queue.Spec.Capability[name]; found {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// reachCapability returns whether the allocated resources of queue reach its
+// capability in any resource listed by it.
+func (attr *queueAttr) reachCapability() bool {
+	for _, name := range attr.capped {
+		allocated := attr.allocated.Get(name)
+		if allocated > 0 && allocated >= attr.capability.Get(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// fitCapability returns whether the queue is still in its capability after
+// the resources are allocated, in every resource listed by it.
+func (attr *queueAttr) fitCapability(resreq *api.Resource) bool {
+	for _, name := range attr.capped {
+		if attr.allocated.Get(name)+resreq.Get(name) > attr.capability.Get(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// queueCapability returns the capability of the queue; the resource not listed in
+// the queue's capability is limited by the total resource of the cluster.
+func (pp *proportionPlugin) queueCapability(queue *api.QueueInfo) *api.Resource {
+	rl := queue.Queue.Spec.Capability
+	if len(rl) == 0 {
+		return nil
+	}
+
+	capability := api.NewResource(rl)
+	if _, found := rl[v1.ResourceCPU]; !found {
+		capability.MilliCPU = pp.totalResource.MilliCPU
+	}
+	if _, found := rl[v1.ResourceMemory]; !found {
+		capability.Memory = pp.totalResource.Memory
+	}
+	if _, found := rl[api.GPUResourceName]; !found {
+		capability.MilliGPU = pp.totalResource.MilliGPU
+	}
+
+	return capability
+}
+
 func (pp *proportionPlugin) updateShare(attr *queueAttr) {
 	res := float64(0)
 
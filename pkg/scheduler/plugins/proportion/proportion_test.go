@@ -0,0 +1,348 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proportion
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache/fake"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+func buildResourceList(cpu string, memory string) v1.ResourceList {
+	return v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+func buildNode(name string, alloc v1.ResourceList) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: v1.NodeStatus{
+			Capacity:    alloc,
+			Allocatable: alloc,
+		},
+	}
+}
+
+func buildPod(ns, n, nn string, p v1.PodPhase, req v1.ResourceList, groupName string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       types.UID(fmt.Sprintf("%v-%v", ns, n)),
+			Name:      n,
+			Namespace: ns,
+			Annotations: map[string]string{
+				kbv1.GroupNameAnnotationKey: groupName,
+			},
+		},
+		Status: v1.PodStatus{
+			Phase: p,
+		},
+		Spec: v1.PodSpec{
+			NodeName: nn,
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: req,
+					},
+				},
+			},
+			Priority: new(int32),
+		},
+	}
+}
+
+func buildPodGroup(ns, name, queue string) *kbv1.PodGroup {
+	return &kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+		Spec: kbv1.PodGroupSpec{
+			Queue: queue,
+		},
+	}
+}
+
+type fakeStatusUpdater struct {
+}
+
+func (ftsu *fakeStatusUpdater) UpdatePodCondition(pod *v1.Pod, podCondition *v1.PodCondition) (*v1.Pod, error) {
+	// do nothing here
+	return pod, nil
+}
+
+func (ftsu *fakeStatusUpdater) UpdatePodGroup(pg *kbv1.PodGroup) (*kbv1.PodGroup, error) {
+	// do nothing here
+	return pg, nil
+}
+
+func (ftsu *fakeStatusUpdater) UpdateQueueStatus(queue *kbv1.Queue) (*kbv1.Queue, error) {
+	// do nothing here
+	return queue, nil
+}
+
+func buildChildQueue(name, parent string, weight int32) *kbv1.Queue {
+	queue := buildQueue(name, weight, nil, nil)
+	queue.Spec.Parent = parent
+	return queue
+}
+
+func buildQueue(name string, weight int32, capability, guarantee v1.ResourceList) *kbv1.Queue {
+	return &kbv1.Queue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: kbv1.QueueSpec{
+			Weight:     weight,
+			Capability: capability,
+			Guarantee:  guarantee,
+		},
+	}
+}
+
+func TestDeserved(t *testing.T) {
+	var pods []*v1.Pod
+	for i := 0; i < 10; i++ {
+		pods = append(pods,
+			buildPod("c1", fmt.Sprintf("p%d", i), "", v1.PodPending, buildResourceList("1", "1G"), "pg1"),
+			buildPod("c2", fmt.Sprintf("p%d", i), "", v1.PodPending, buildResourceList("1", "1G"), "pg2"))
+	}
+
+	tests := []struct {
+		name     string
+		queues   []*kbv1.Queue
+		expected map[api.QueueID]float64
+	}{
+		{
+			name: "weight only",
+			queues: []*kbv1.Queue{
+				buildQueue("q1", 1, nil, nil),
+				buildQueue("q2", 1, nil, nil),
+			},
+			expected: map[api.QueueID]float64{
+				"q1": 5000,
+				"q2": 5000,
+			},
+		},
+		{
+			name: "guarantee is funded before weight",
+			queues: []*kbv1.Queue{
+				buildQueue("q1", 1, nil, v1.ResourceList{v1.ResourceCPU: resource.MustParse("6")}),
+				buildQueue("q2", 1, nil, nil),
+			},
+			expected: map[api.QueueID]float64{
+				"q1": 8000,
+				"q2": 2000,
+			},
+		},
+		{
+			name: "capability caps deserved",
+			queues: []*kbv1.Queue{
+				buildQueue("q1", 1, nil, v1.ResourceList{v1.ResourceCPU: resource.MustParse("6")}),
+				buildQueue("q2", 1, v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}, nil),
+			},
+			expected: map[api.QueueID]float64{
+				"q1": 9000,
+				"q2": 1000,
+			},
+		},
+	}
+
+	for i, test := range tests {
+		schedulerCache := &cache.SchedulerCache{
+			Nodes:         make(map[string]*api.NodeInfo),
+			Jobs:          make(map[api.JobID]*api.JobInfo),
+			Queues:        make(map[api.QueueID]*api.QueueInfo),
+			StatusUpdater: &fakeStatusUpdater{},
+			Recorder:      record.NewFakeRecorder(100),
+		}
+		schedulerCache.AddNode(buildNode("n1", buildResourceList("10", "20G")))
+		for _, pod := range pods {
+			schedulerCache.AddPod(pod)
+		}
+		schedulerCache.AddPodGroup(buildPodGroup("c1", "pg1", "q1"))
+		schedulerCache.AddPodGroup(buildPodGroup("c2", "pg2", "q2"))
+		for _, q := range test.queues {
+			schedulerCache.AddQueue(q)
+		}
+
+		pp := New(nil).(*proportionPlugin)
+		framework.RegisterPluginBuilder(pp.Name(), func(map[string]string) framework.Plugin {
+			return pp
+		})
+
+		ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+			{
+				Plugins: []conf.PluginOption{
+					{
+						Name: pp.Name(),
+					},
+				},
+			},
+		})
+
+		for queue, deserved := range test.expected {
+			if got := pp.queueOpts[queue].deserved.MilliCPU; got != deserved {
+				t.Errorf("case %d (%s): expected deserved cpu of queue <%s> to be %v, got %v",
+					i, test.name, queue, deserved, got)
+			}
+		}
+
+		framework.CloseSession(ssn)
+		framework.CleanupPluginBuilders()
+
+		for queue, deserved := range test.expected {
+			status := schedulerCache.Queues[queue].Queue.Status
+			if got := status.Deserved.Cpu().MilliValue(); float64(got) != deserved {
+				t.Errorf("case %d (%s): expected deserved cpu in status of queue <%s> to be %v, got %v",
+					i, test.name, queue, deserved, got)
+			}
+			if status.Running != 1 {
+				t.Errorf("case %d (%s): expected 1 running PodGroup in status of queue <%s>, got %v",
+					i, test.name, queue, status.Running)
+			}
+		}
+	}
+}
+
+func TestHierarchicalDeserved(t *testing.T) {
+	schedulerCache := &cache.SchedulerCache{
+		Nodes:         make(map[string]*api.NodeInfo),
+		Jobs:          make(map[api.JobID]*api.JobInfo),
+		Queues:        make(map[api.QueueID]*api.QueueInfo),
+		StatusUpdater: &fakeStatusUpdater{},
+		Recorder:      record.NewFakeRecorder(100),
+	}
+	schedulerCache.AddNode(buildNode("n1", buildResourceList("10", "20G")))
+	for i := 0; i < 10; i++ {
+		schedulerCache.AddPod(buildPod("c1", fmt.Sprintf("p%d", i), "", v1.PodPending, buildResourceList("1", "1G"), "pg1"))
+		schedulerCache.AddPod(buildPod("c2", fmt.Sprintf("p%d", i), "", v1.PodPending, buildResourceList("1", "1G"), "pg2"))
+		schedulerCache.AddPod(buildPod("c3", fmt.Sprintf("p%d", i), "", v1.PodPending, buildResourceList("1", "1G"), "pg3"))
+	}
+	schedulerCache.AddPodGroup(buildPodGroup("c1", "pg1", "team-a"))
+	schedulerCache.AddPodGroup(buildPodGroup("c2", "pg2", "team-b"))
+	schedulerCache.AddPodGroup(buildPodGroup("c3", "pg3", "other"))
+	for _, q := range []*kbv1.Queue{
+		buildQueue("org", 1, nil, nil),
+		buildQueue("other", 1, nil, nil),
+		buildChildQueue("team-a", "org", 3),
+		buildChildQueue("team-b", "org", 1),
+	} {
+		schedulerCache.AddQueue(q)
+	}
+
+	pp := New(map[string]string{Hierarchical: "true"}).(*proportionPlugin)
+	framework.RegisterPluginBuilder(pp.Name(), func(map[string]string) framework.Plugin {
+		return pp
+	})
+	defer framework.CleanupPluginBuilders()
+
+	ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name: pp.Name(),
+				},
+			},
+		},
+	})
+	defer framework.CloseSession(ssn)
+
+	expected := map[api.QueueID]float64{
+		"org":    5000,
+		"other":  5000,
+		"team-a": 3750,
+		"team-b": 1250,
+	}
+	for queue, deserved := range expected {
+		if got := pp.queueOpts[queue].deserved.MilliCPU; got != deserved {
+			t.Errorf("expected deserved cpu of queue <%s> to be %v, got %v",
+				queue, deserved, got)
+		}
+	}
+
+	if ssn.Overused(ssn.Queues["team-b"]) {
+		t.Errorf("expected queue <team-b> not to be overused")
+	}
+}
+
+func TestCapability(t *testing.T) {
+	pp := New(nil).(*proportionPlugin)
+	framework.RegisterPluginBuilder(pp.Name(), func(map[string]string) framework.Plugin {
+		return pp
+	})
+	defer framework.CleanupPluginBuilders()
+
+	// Queue q1 is capped only on CPU, so its memory is not limited.
+	fc := fake.New(
+		buildNode("n1", buildResourceList("10", "20G")),
+		buildQueue("q1", 1, v1.ResourceList{v1.ResourceCPU: resource.MustParse("3")}, nil),
+		buildPodGroup("c1", "pg1", "q1"),
+		buildPod("c1", "p0", "n1", v1.PodRunning, buildResourceList("1", "1G"), "pg1"),
+		buildPod("c1", "p1", "n1", v1.PodRunning, buildResourceList("1", "1G"), "pg1"),
+		buildPod("c1", "p2", "", v1.PodPending, buildResourceList("2", "1G"), "pg1"),
+		buildPod("c1", "p3", "", v1.PodPending, buildResourceList("1", "1G"), "pg1"),
+	)
+
+	ssn := framework.OpenSession(fc, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name: pp.Name(),
+				},
+			},
+		},
+	})
+	defer framework.CloseSession(ssn)
+
+	queue := ssn.Queues["q1"]
+	job := ssn.Jobs["c1/pg1"]
+	n1 := ssn.Nodes["n1"]
+
+	if ssn.Overused(queue) {
+		t.Errorf("expected queue q1 is not overused")
+	}
+	if err := ssn.PredicateFn(job.Tasks["c1-p2"], n1); err == nil {
+		t.Errorf("expected p2 exceeds the capability of queue q1")
+	}
+	if err := ssn.PredicateFn(job.Tasks["c1-p3"], n1); err != nil {
+		t.Errorf("expected p3 fits the capability of queue q1, got %v", err)
+	}
+
+	// The queue is overused once it reaches the capability of CPU.
+	if err := ssn.Allocate(job.Tasks["c1-p3"], "n1", false); err != nil {
+		t.Fatalf("failed to allocate p3: %v", err)
+	}
+	if !ssn.Overused(queue) {
+		t.Errorf("expected queue q1 is overused")
+	}
+}
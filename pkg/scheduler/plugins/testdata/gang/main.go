@@ -0,0 +1,38 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package main is a custom plugin for test, which is named by its file and
+// clashes with the built-in gang plugin.
+package main
+
+import (
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+type gangPlugin struct{}
+
+// New returns the plugin.
+func New(arguments map[string]string) framework.Plugin {
+	return &gangPlugin{}
+}
+
+func (gp *gangPlugin) Name() string {
+	return "gang"
+}
+
+func (gp *gangPlugin) OnSessionOpen(ssn *framework.Session) {}
+
+func (gp *gangPlugin) OnSessionClose(ssn *framework.Session) {}
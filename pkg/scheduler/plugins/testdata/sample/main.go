@@ -0,0 +1,49 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package main is a custom plugin for test, built by
+// `go build -buildmode=plugin`.
+package main
+
+import (
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+// PluginName is the name of plugin.
+var PluginName = "sample"
+
+// Arguments is the argument schema of plugin.
+var Arguments = framework.ArgumentSchema{
+	"sample.weight": framework.ArgumentInt,
+}
+
+type samplePlugin struct{}
+
+// New panics without arguments, so it must not be called when the plugin is loaded.
+func New(arguments map[string]string) framework.Plugin {
+	if _, found := arguments["sample.weight"]; !found {
+		panic("argument sample.weight is required")
+	}
+	return &samplePlugin{}
+}
+
+func (sp *samplePlugin) Name() string {
+	return PluginName
+}
+
+func (sp *samplePlugin) OnSessionOpen(ssn *framework.Session) {}
+
+func (sp *samplePlugin) OnSessionClose(ssn *framework.Session) {}
@@ -0,0 +1,227 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/golang/glog"
+
+	schedulerapi "k8s.io/kubernetes/pkg/scheduler/api"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+const (
+	// TopologyKey is the key for providing the node label key of topology domain in YAML
+	TopologyKey = "topology.key"
+)
+
+// Arguments is the argument schema of topology plugin.
+var Arguments = framework.ArgumentSchema{
+	TopologyKey: framework.ArgumentString,
+}
+
+type topologyPlugin struct {
+	// topologyKey is the node label key of topology domain, e.g. rack or zone.
+	topologyKey string
+	sync.Mutex
+	// pickedDomains is the topology domain picked for the task of job which has
+	// no task placed yet; it's kept only for the predicates and node orders of
+	// the task, so the domain is picked again for the next task if the task is
+	// not placed.
+	pickedDomains map[api.JobID]pickedDomain
+	// Arguments given for the plugin
+	pluginArguments map[string]string
+}
+
+type pickedDomain struct {
+	task   api.TaskID
+	domain string
+}
+
+func New(arguments map[string]string) framework.Plugin {
+	return &topologyPlugin{
+		topologyKey:     arguments[TopologyKey],
+		pickedDomains:   map[api.JobID]pickedDomain{},
+		pluginArguments: arguments,
+	}
+}
+
+func (tp *topologyPlugin) Name() string {
+	return "topology"
+}
+
+// ConcurrencySafe returns true as the domains picked for jobs are guarded by lock.
+func (tp *topologyPlugin) ConcurrencySafe() bool {
+	return true
+}
+
+// topologyAware returns whether the tasks of job should be placed in one topology domain.
+func (tp *topologyPlugin) topologyAware(job *api.JobInfo) bool {
+	if job == nil || job.PodGroup == nil || len(tp.topologyKey) == 0 {
+		return false
+	}
+
+	key, found := job.PodGroup.Annotations[v1alpha1.TopologyKeyAnnotationKey]
+	if !found {
+		return false
+	}
+	if key != tp.topologyKey {
+		glog.V(4).Infof("Topology key <%s> of job <%s/%s> does not match <%s>, ignore it.",
+			key, job.Namespace, job.Name, tp.topologyKey)
+		return false
+	}
+
+	return true
+}
+
+// placedDomain returns the topology domain of the tasks of job which are placed.
+func (tp *topologyPlugin) placedDomain(ssn *framework.Session, job *api.JobInfo) (string, bool) {
+	for status, tasks := range job.TaskStatusIndex {
+		if !api.AllocatedStatus(status) && status != api.Pipelined {
+			continue
+		}
+		for _, task := range tasks {
+			if node, found := ssn.Nodes[task.NodeName]; found && node.Node != nil {
+				if domain, found := node.Node.Labels[tp.topologyKey]; found {
+					return domain, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+// pickDomain returns the topology domain with the most free room; the domains which
+// can hold the pending tasks of job are preferred.
+func (tp *topologyPlugin) pickDomain(ssn *framework.Session, job *api.JobInfo) (string, bool) {
+	idles := map[string]*api.Resource{}
+	for _, node := range ssn.Nodes {
+		if node.Node == nil {
+			continue
+		}
+		domain, found := node.Node.Labels[tp.topologyKey]
+		if !found {
+			continue
+		}
+		if _, found := idles[domain]; !found {
+			idles[domain] = api.EmptyResource()
+		}
+		idles[domain].Add(node.Idle)
+	}
+
+	request := api.EmptyResource()
+	for _, task := range job.TaskStatusIndex[api.Pending] {
+		request.Add(task.Resreq)
+	}
+
+	var picked string
+	var pickedIdle *api.Resource
+	for domain, idle := range idles {
+		if pickedIdle == nil || roomier(request, idle, pickedIdle) ||
+			(!roomier(request, pickedIdle, idle) && domain < picked) {
+			picked, pickedIdle = domain, idle
+		}
+	}
+
+	return picked, pickedIdle != nil
+}
+
+// roomier returns whether l has more free room than r for the request.
+func roomier(request, l, r *api.Resource) bool {
+	lFit, rFit := request.LessEqual(l), request.LessEqual(r)
+	if lFit != rFit {
+		return lFit
+	}
+	if l.MilliCPU != r.MilliCPU {
+		return l.MilliCPU > r.MilliCPU
+	}
+	if l.Memory != r.Memory {
+		return l.Memory > r.Memory
+	}
+	return l.MilliGPU > r.MilliGPU
+}
+
+// jobDomain returns the topology domain of job for the task: the domain of the placed
+// tasks of job, or the domain picked for the task if no task of job is placed.
+func (tp *topologyPlugin) jobDomain(ssn *framework.Session, job *api.JobInfo, task *api.TaskInfo) (string, bool) {
+	if domain, found := tp.placedDomain(ssn, job); found {
+		return domain, true
+	}
+
+	// The predicates of nodes are evaluated concurrently.
+	tp.Lock()
+	defer tp.Unlock()
+
+	if picked, found := tp.pickedDomains[job.UID]; found && picked.task == task.UID {
+		return picked.domain, true
+	}
+
+	domain, found := tp.pickDomain(ssn, job)
+	if found {
+		glog.V(3).Infof("Topology domain <%s=%s> is picked for job <%s/%s>.",
+			tp.topologyKey, domain, job.Namespace, job.Name)
+		tp.pickedDomains[job.UID] = pickedDomain{task: task.UID, domain: domain}
+	}
+
+	return domain, found
+}
+
+func (tp *topologyPlugin) OnSessionOpen(ssn *framework.Session) {
+	ssn.AddPredicateFn(tp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) error {
+		job := ssn.Jobs[task.Job]
+		if !tp.topologyAware(job) {
+			return nil
+		}
+
+		domain, found := tp.jobDomain(ssn, job, task)
+		if !found {
+			return fmt.Errorf("no topology domain <%s> for job <%s/%s>",
+				tp.topologyKey, job.Namespace, job.Name)
+		}
+
+		if node.Node == nil || node.Node.Labels[tp.topologyKey] != domain {
+			return fmt.Errorf("node <%s> is not in topology domain <%s=%s> of job <%s/%s>",
+				node.Name, tp.topologyKey, domain, job.Namespace, job.Name)
+		}
+
+		return nil
+	})
+
+	ssn.AddNodeOrderFn(tp.Name(), func(task *api.TaskInfo, node *api.NodeInfo) (int, error) {
+		job := ssn.Jobs[task.Job]
+		if !tp.topologyAware(job) {
+			return 0, nil
+		}
+
+		if domain, found := tp.jobDomain(ssn, job, task); found &&
+			node.Node != nil && node.Node.Labels[tp.topologyKey] == domain {
+			return schedulerapi.MaxPriority, nil
+		}
+
+		return 0, nil
+	})
+}
+
+func (tp *topologyPlugin) OnSessionClose(ssn *framework.Session) {
+	tp.pickedDomains = nil
+}
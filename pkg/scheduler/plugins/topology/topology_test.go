@@ -0,0 +1,258 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"fmt"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache/fake"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+const zoneKey = "failure-domain.beta.kubernetes.io/zone"
+
+func buildResourceList(cpu string, memory string) v1.ResourceList {
+	return v1.ResourceList{
+		v1.ResourceCPU:    resource.MustParse(cpu),
+		v1.ResourceMemory: resource.MustParse(memory),
+	}
+}
+
+func buildNode(name, zone string, alloc v1.ResourceList) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{zoneKey: zone},
+		},
+		Status: v1.NodeStatus{
+			Capacity:    alloc,
+			Allocatable: alloc,
+		},
+	}
+}
+
+func buildPod(ns, n, nn string, p v1.PodPhase, req v1.ResourceList, groupName string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			UID:       types.UID(fmt.Sprintf("%v-%v", ns, n)),
+			Name:      n,
+			Namespace: ns,
+			Annotations: map[string]string{
+				kbv1.GroupNameAnnotationKey: groupName,
+			},
+		},
+		Status: v1.PodStatus{
+			Phase: p,
+		},
+		Spec: v1.PodSpec{
+			NodeName: nn,
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: req,
+					},
+				},
+			},
+			Priority: new(int32),
+		},
+	}
+}
+
+type fakeStatusUpdater struct {
+}
+
+func (ftsu *fakeStatusUpdater) UpdatePodCondition(pod *v1.Pod, podCondition *v1.PodCondition) (*v1.Pod, error) {
+	// do nothing here
+	return pod, nil
+}
+
+func (ftsu *fakeStatusUpdater) UpdatePodGroup(pg *kbv1.PodGroup) (*kbv1.PodGroup, error) {
+	// do nothing here
+	return pg, nil
+}
+
+func (ftsu *fakeStatusUpdater) UpdateQueueStatus(queue *kbv1.Queue) (*kbv1.Queue, error) {
+	// do nothing here
+	return queue, nil
+}
+
+func TestTopologyPredicate(t *testing.T) {
+	framework.RegisterPluginBuilder("topology", New)
+	defer framework.CleanupPluginBuilders()
+
+	tests := []struct {
+		name     string
+		pods     []*v1.Pod
+		expected map[string]bool
+	}{
+		{
+			name: "pick the zone with most free room",
+			pods: []*v1.Pod{
+				buildPod("c1", "p1", "", v1.PodPending, buildResourceList("1", "1G"), "pg1"),
+				buildPod("c1", "p2", "", v1.PodPending, buildResourceList("1", "1G"), "pg1"),
+			},
+			expected: map[string]bool{
+				"a1": false,
+				"a2": false,
+				"b1": true,
+			},
+		},
+		{
+			name: "keep in the zone of placed tasks",
+			pods: []*v1.Pod{
+				buildPod("c1", "p1", "a1", v1.PodRunning, buildResourceList("1", "1G"), "pg1"),
+				buildPod("c1", "p2", "", v1.PodPending, buildResourceList("1", "1G"), "pg1"),
+			},
+			expected: map[string]bool{
+				"a1": true,
+				"a2": true,
+				"b1": false,
+			},
+		},
+	}
+
+	for i, test := range tests {
+		schedulerCache := &cache.SchedulerCache{
+			Nodes:         make(map[string]*api.NodeInfo),
+			Jobs:          make(map[api.JobID]*api.JobInfo),
+			Queues:        make(map[api.QueueID]*api.QueueInfo),
+			StatusUpdater: &fakeStatusUpdater{},
+			Recorder:      record.NewFakeRecorder(100),
+		}
+		schedulerCache.AddNode(buildNode("a1", "a", buildResourceList("2", "4G")))
+		schedulerCache.AddNode(buildNode("a2", "a", buildResourceList("2", "4G")))
+		schedulerCache.AddNode(buildNode("b1", "b", buildResourceList("8", "16G")))
+		for _, pod := range test.pods {
+			schedulerCache.AddPod(pod)
+		}
+		schedulerCache.AddPodGroup(&kbv1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "pg1",
+				Namespace:   "c1",
+				Annotations: map[string]string{kbv1.TopologyKeyAnnotationKey: zoneKey},
+			},
+			Spec: kbv1.PodGroupSpec{
+				Queue: "q1",
+			},
+		})
+		schedulerCache.AddQueue(&kbv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "q1",
+			},
+		})
+
+		ssn := framework.OpenSession(schedulerCache, []conf.Tier{
+			{
+				Plugins: []conf.PluginOption{
+					{
+						Name:      "topology",
+						Arguments: map[string]string{TopologyKey: zoneKey},
+					},
+				},
+			},
+		})
+
+		if !ssn.ConcurrencySafe() {
+			t.Errorf("case %d (%s): expected session is concurrency safe", i, test.name)
+		}
+
+		task := ssn.Jobs["c1/pg1"].TaskStatusIndex[api.Pending]["c1-p2"]
+		for name, expected := range test.expected {
+			err := ssn.PredicateFn(task, ssn.Nodes[name])
+			if (err == nil) != expected {
+				t.Errorf("case %d (%s): expected predicate on node <%s> to be %v, got error %v",
+					i, test.name, name, expected, err)
+			}
+		}
+
+		framework.CloseSession(ssn)
+	}
+}
+
+func TestTopologyDomainRepicked(t *testing.T) {
+	framework.RegisterPluginBuilder("topology", New)
+	defer framework.CleanupPluginBuilders()
+
+	fc := fake.New(
+		buildNode("a1", "a", buildResourceList("4", "8G")),
+		buildNode("b1", "b", buildResourceList("8", "16G")),
+		// The node of p0 is not in cache yet.
+		buildPod("c1", "p0", "unknown", v1.PodRunning, buildResourceList("1", "1G"), "pg1"),
+		buildPod("c1", "p1", "", v1.PodPending, buildResourceList("1", "1G"), "pg1"),
+		buildPod("c1", "p2", "", v1.PodPending, buildResourceList("1", "1G"), "pg1"),
+		buildPod("c1", "p3", "", v1.PodPending, buildResourceList("8", "1G"), "pg2"),
+		&kbv1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "pg1",
+				Namespace:   "c1",
+				Annotations: map[string]string{kbv1.TopologyKeyAnnotationKey: zoneKey},
+			},
+			Spec: kbv1.PodGroupSpec{Queue: "q1"},
+		},
+		&kbv1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "pg2", Namespace: "c1"},
+			Spec:       kbv1.PodGroupSpec{Queue: "q1"},
+		},
+		&kbv1.Queue{ObjectMeta: metav1.ObjectMeta{Name: "q1"}},
+	)
+
+	ssn := framework.OpenSession(fc, []conf.Tier{
+		{
+			Plugins: []conf.PluginOption{
+				{
+					Name:      "topology",
+					Arguments: map[string]string{TopologyKey: zoneKey},
+				},
+			},
+		},
+	})
+	defer framework.CloseSession(ssn)
+
+	ssn.Nodes["unknown"] = api.NewNodeInfo(nil)
+
+	pg1 := ssn.Jobs["c1/pg1"]
+	p1 := pg1.TaskStatusIndex[api.Pending]["c1-p1"]
+	p2 := pg1.TaskStatusIndex[api.Pending]["c1-p2"]
+
+	// Zone b is picked for p1, but p1 is not placed, e.g. pg1 is discarded.
+	if err := ssn.PredicateFn(p1, ssn.Nodes["b1"]); err != nil {
+		t.Fatalf("expected p1 fits in zone b, got %v", err)
+	}
+
+	// Zone b is full after p3 is allocated, so zone a is picked for p2.
+	p3 := ssn.Jobs["c1/pg2"].TaskStatusIndex[api.Pending]["c1-p3"]
+	if err := ssn.Statement().Allocate(p3, "b1", false); err != nil {
+		t.Fatalf("failed to allocate p3: %v", err)
+	}
+	if err := ssn.PredicateFn(p2, ssn.Nodes["a1"]); err != nil {
+		t.Errorf("expected p2 fits in zone a, got %v", err)
+	}
+	if err := ssn.PredicateFn(p2, ssn.Nodes["b1"]); err == nil {
+		t.Errorf("expected p2 does not fit in zone b")
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"sort"
+	"time"
+
+	"k8s.io/api/core/v1"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	schedcache "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+// ReplayDecision is a decision on a task made by replay.
+type ReplayDecision struct {
+	Namespace string
+	Name      string
+	NodeName  string
+	// Reason is the reason of eviction.
+	Reason string
+}
+
+// ReplayResult is the decisions made by replaying a cluster dump, sorted by
+// namespace and name of tasks.
+type ReplayResult struct {
+	Binds     []ReplayDecision
+	Pipelines []ReplayDecision
+	Evictions []ReplayDecision
+}
+
+// replayClient accepts all binds, evictions and status updates of replay
+// without doing anything.
+type replayClient struct{}
+
+func (rc *replayClient) Bind(pod *v1.Pod, hostname string) error {
+	return nil
+}
+
+func (rc *replayClient) Evict(pod *v1.Pod) error {
+	return nil
+}
+
+func (rc *replayClient) UpdatePodCondition(pod *v1.Pod, podCondition *v1.PodCondition) (*v1.Pod, error) {
+	return pod, nil
+}
+
+func (rc *replayClient) UpdatePodGroup(pg *v1alpha1.PodGroup) (*v1alpha1.PodGroup, error) {
+	return pg, nil
+}
+
+func (rc *replayClient) UpdateQueueStatus(queue *v1alpha1.Queue) (*v1alpha1.Queue, error) {
+	return queue, nil
+}
+
+func (rc *replayClient) AllocateVolumes(task *api.TaskInfo, hostname string) error {
+	return nil
+}
+
+func (rc *replayClient) RevertVolumes(task *api.TaskInfo) {
+}
+
+func (rc *replayClient) BindVolumes(task *api.TaskInfo) error {
+	return nil
+}
+
+// Replay runs one scheduling cycle of the actions of scheduler configuration,
+// e.g. loaded by LoadSchedulerConf, against the cluster dump in memory, and
+// returns the decisions made. The intervals of actions are ignored.
+func Replay(dump *schedcache.ClusterDump, actions []framework.Action, actionOptions map[string]conf.ActionOption,
+	tiers []conf.Tier, enablePreemption bool) *ReplayResult {
+	client := &replayClient{}
+	cache := schedcache.NewFromDump(dump)
+	cache.Binder = client
+	cache.Evictor = client
+	cache.StatusUpdater = client
+	cache.VolumeBinder = client
+
+	ssn := framework.OpenSessionWithConf(cache, tiers, actionOptions, enablePreemption)
+
+	// The decisions are tracked by events, so the ones discarded by
+	// statement are dropped.
+	binds := map[api.TaskID]ReplayDecision{}
+	pipelines := map[api.TaskID]ReplayDecision{}
+	evictions := map[api.TaskID]ReplayDecision{}
+	decision := func(event *framework.Event) ReplayDecision {
+		return ReplayDecision{
+			Namespace: event.Task.Namespace,
+			Name:      event.Task.Name,
+			NodeName:  event.NodeName,
+			Reason:    event.Reason,
+		}
+	}
+	ssn.AddEventHandler(&framework.EventHandler{
+		EventFuncs: map[framework.EventType]func(event *framework.Event){
+			framework.DispatchEvent: func(event *framework.Event) {
+				binds[event.Task.UID] = decision(event)
+			},
+			framework.PipelineEvent: func(event *framework.Event) {
+				pipelines[event.Task.UID] = decision(event)
+			},
+			framework.UnpipelineEvent: func(event *framework.Event) {
+				delete(pipelines, event.Task.UID)
+			},
+			framework.EvictEvent: func(event *framework.Event) {
+				evictions[event.Task.UID] = decision(event)
+			},
+			framework.UnevictEvent: func(event *framework.Event) {
+				delete(evictions, event.Task.UID)
+			},
+		},
+	})
+
+	framework.ExecuteActions(ssn, actions, actionOptions, nil, time.Now())
+	framework.CloseSession(ssn)
+
+	return &ReplayResult{
+		Binds:     sortDecisions(binds),
+		Pipelines: sortDecisions(pipelines),
+		Evictions: sortDecisions(evictions),
+	}
+}
+
+func sortDecisions(decisions map[api.TaskID]ReplayDecision) []ReplayDecision {
+	result := make([]ReplayDecision, 0, len(decisions))
+	for _, d := range decisions {
+		result = append(result, d)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	schedcache "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+)
+
+func buildReplayDump() *schedcache.ClusterDump {
+	resources := func(cpu string) v1.ResourceList {
+		return v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse(cpu),
+			v1.ResourceMemory: resource.MustParse("1G"),
+			v1.ResourcePods:   resource.MustParse("10"),
+		}
+	}
+
+	dump := &schedcache.ClusterDump{
+		APIVersion: schedcache.DumpAPIVersion,
+		Kind:       schedcache.DumpKind,
+		Queues: []*kbv1.Queue{
+			{ObjectMeta: metav1.ObjectMeta{Name: "q1"}, Spec: kbv1.QueueSpec{Weight: 1}},
+		},
+	}
+
+	for _, name := range []string{"n1", "n2"} {
+		dump.Nodes = append(dump.Nodes, &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     v1.NodeStatus{Capacity: resources("2"), Allocatable: resources("2")},
+		})
+	}
+
+	// pg1 fits into the cluster, but pg2 does not.
+	for pg, tasks := range map[string]struct {
+		min int32
+		cpu string
+	}{
+		"pg1": {min: 2, cpu: "2"},
+		"pg2": {min: 3, cpu: "1"},
+	} {
+		dump.PodGroups = append(dump.PodGroups, &kbv1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "c1", Name: pg},
+			Spec:       kbv1.PodGroupSpec{MinMember: tasks.min, Queue: "q1"},
+		})
+
+		for i := 0; i < int(tasks.min); i++ {
+			name := fmt.Sprintf("%s-%d", pg, i)
+			dump.Pods = append(dump.Pods, &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					UID:         types.UID("c1-" + name),
+					Namespace:   "c1",
+					Name:        name,
+					Annotations: map[string]string{kbv1.GroupNameAnnotationKey: pg},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse(tasks.cpu),
+							v1.ResourceMemory: resource.MustParse("100M"),
+						}}},
+					},
+					Priority: new(int32),
+				},
+				Status: v1.PodStatus{Phase: v1.PodPending},
+			})
+		}
+	}
+
+	return dump
+}
+
+func TestReplay(t *testing.T) {
+	actions, actionOptions, tiers, err := LoadSchedulerConf("")
+	if err != nil {
+		t.Fatalf("failed to load default scheduler configuration: %v", err)
+	}
+	result := Replay(buildReplayDump(), actions, actionOptions, tiers, false)
+
+	nodes := map[string]string{}
+	for _, d := range result.Binds {
+		nodes[d.Name] = d.NodeName
+	}
+	if len(nodes) != 2 || len(nodes["pg1-0"]) == 0 || len(nodes["pg1-1"]) == 0 || nodes["pg1-0"] == nodes["pg1-1"] {
+		t.Errorf("expected tasks of pg1 are bound to different nodes, got %v", result.Binds)
+	}
+	if len(result.Pipelines) != 0 || len(result.Evictions) != 0 {
+		t.Errorf("expected no pipeline or eviction, got %v, %v", result.Pipelines, result.Evictions)
+	}
+}
+
+func TestDumpHandler(t *testing.T) {
+	dump := buildReplayDump()
+	pc := &Scheduler{cache: schedcache.NewFromDump(dump)}
+	server := httptest.NewServer(pc.DumpHandler())
+	defer server.Close()
+
+	for query, code := range map[string]int{
+		"":              http.StatusOK,
+		"?format=json":  http.StatusOK,
+		"?format=yaml":  http.StatusOK,
+		"?format=proto": http.StatusBadRequest,
+	} {
+		resp, err := http.Get(server.URL + DumpPath + query)
+		if err != nil {
+			t.Fatalf("failed to get %s: %v", query, err)
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", query, err)
+		}
+
+		if resp.StatusCode != code {
+			t.Errorf("query %q: expected %d, got %d", query, code, resp.StatusCode)
+			continue
+		}
+		if code != http.StatusOK {
+			continue
+		}
+
+		served, err := schedcache.DecodeClusterDump(data)
+		if err != nil {
+			t.Errorf("query %q: failed to decode dump: %v", query, err)
+			continue
+		}
+		var names []string
+		for _, pod := range served.Pods {
+			names = append(names, pod.Name)
+		}
+		expected := []string{"pg1-0", "pg1-1", "pg2-0", "pg2-1", "pg2-2"}
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("query %q: expected pods %v, got %v", query, expected, names)
+		}
+	}
+}
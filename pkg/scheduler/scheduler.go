@@ -17,10 +17,12 @@ limitations under the License.
 package scheduler
 
 import (
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
 
+	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/rest"
 
@@ -30,14 +32,33 @@ import (
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/metrics"
 )
 
+// confCheckPeriod is the period to check whether scheduler configuration
+// file is changed; a mounted ConfigMap is updated by kubelet in about one
+// minute, so it's not necessary to watch it closely.
+const confCheckPeriod = 10 * time.Second
+
+// ConfReloadFailedReason is the reason of event when failed to reload
+// scheduler configuration.
+const ConfReloadFailedReason = "ConfReloadFailed"
+
+// ConfLoadFailedReason is the reason of event when failed to load scheduler
+// configuration at startup, and the default configuration is used.
+const ConfLoadFailedReason = "ConfLoadFailed"
+
 type Scheduler struct {
 	cache          schedcache.Cache
 	config         *rest.Config
+	mutex          sync.Mutex
 	actions        []framework.Action
+	actionOptions  map[string]conf.ActionOption
+	lastRuns       framework.ActionRuns
 	plugins        []conf.Tier
 	schedulerConf  string
+	loadedConf     string
 	schedulePeriod time.Duration
 	enablePreemption bool
+	// trace is the scheduling decisions of the latest session.
+	trace *framework.Trace
 }
 
 func NewScheduler(
@@ -71,35 +92,99 @@ func (pc *Scheduler) Run(stopCh <-chan struct{}) {
 	schedConf := defaultSchedulerConf
 	if len(pc.schedulerConf) != 0 {
 		if schedConf, err = readSchedulerConf(pc.schedulerConf); err != nil {
-			glog.Errorf("Failed to read scheduler configuration '%s', using default configuration: %v",
-				pc.schedulerConf, err)
+			pc.confLoadFailed(err)
 			schedConf = defaultSchedulerConf
 		}
 	}
 
-	pc.actions, pc.plugins, err = loadSchedulerConf(schedConf)
+	pc.actions, pc.actionOptions, pc.plugins, err = loadSchedulerConf(schedConf)
 	if err != nil {
-		panic(err)
+		pc.confLoadFailed(err)
+		pc.actions, pc.actionOptions, pc.plugins, err = loadSchedulerConf(defaultSchedulerConf)
+		if err != nil {
+			glog.Exitf("Failed to load default scheduler configuration: %v", err)
+		}
+	}
+	// The invalid configuration is not retried until it's changed.
+	pc.loadedConf = schedConf
+
+	if len(pc.schedulerConf) != 0 {
+		go wait.Until(pc.reloadSchedulerConf, confCheckPeriod, stopCh)
 	}
 
 	go wait.Until(pc.runOnce, pc.schedulePeriod, stopCh)
 }
 
+// reloadSchedulerConf reloads scheduler configuration if the file is changed;
+// the new actions and tiers take effect from next scheduling cycle. If the
+// configuration failed to load, the previous one is kept.
+func (pc *Scheduler) reloadSchedulerConf() {
+	schedConf, err := readSchedulerConf(pc.schedulerConf)
+	if err != nil {
+		pc.confReloadFailed(err)
+		return
+	}
+
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	if schedConf == pc.loadedConf {
+		return
+	}
+	// Do not retry the same content until it's changed again.
+	pc.loadedConf = schedConf
+
+	actions, actionOptions, plugins, err := loadSchedulerConf(schedConf)
+	if err != nil {
+		pc.confReloadFailed(err)
+		return
+	}
+
+	pc.actions, pc.actionOptions, pc.plugins = actions, actionOptions, plugins
+	metrics.RegisterConfReload("success")
+	glog.Infof("Reloaded scheduler configuration '%s'", pc.schedulerConf)
+}
+
+func (pc *Scheduler) confLoadFailed(err error) {
+	glog.Errorf("Failed to load scheduler configuration '%s', using default configuration: %v",
+		pc.schedulerConf, err)
+	pc.cache.RecordEvent(v1.EventTypeWarning, ConfLoadFailedReason,
+		"Failed to load scheduler configuration "+pc.schedulerConf+", using default configuration: "+err.Error())
+}
+
+func (pc *Scheduler) confReloadFailed(err error) {
+	glog.Errorf("Failed to reload scheduler configuration '%s', keep the previous one: %v",
+		pc.schedulerConf, err)
+	metrics.RegisterConfReload("failure")
+	pc.cache.RecordEvent(v1.EventTypeWarning, ConfReloadFailedReason,
+		"Failed to reload scheduler configuration "+pc.schedulerConf+": "+err.Error())
+}
+
 func (pc *Scheduler) runOnce() {
 	glog.V(4).Infof("Start scheduling ...")
 	scheduleStartTime := time.Now()
 	defer glog.V(4).Infof("End scheduling ...")
 	defer metrics.UpdateE2eDuration(metrics.Duration(scheduleStartTime))
 
-	ssn := framework.OpenSession(pc.cache, pc.plugins)
-	ssn.EnablePreemption = pc.enablePreemption
+	pc.mutex.Lock()
+	actions, actionOptions, plugins := pc.actions, pc.actionOptions, pc.plugins
+	pc.mutex.Unlock()
 
-	defer framework.CloseSession(ssn)
+	ssn := framework.OpenSessionWithConf(pc.cache, plugins, actionOptions, pc.enablePreemption)
+	defer func() {
+		framework.CloseSession(ssn)
 
-	glog.V(4).Infof("Start executing ...")
-	for _, action := range pc.actions {
-		actionStartTime := time.Now()
-		action.Execute(ssn)
-		metrics.UpdateActionDuration(action.Name(), metrics.Duration(actionStartTime))
+		pc.mutex.Lock()
+		pc.trace = ssn.Trace
+		pc.mutex.Unlock()
+	}()
+
+	// The last runs of actions are only updated by runOnce, which is never
+	// run concurrently.
+	if pc.lastRuns == nil {
+		pc.lastRuns = framework.ActionRuns{}
 	}
+
+	glog.V(4).Infof("Start executing ...")
+	framework.ExecuteActions(ssn, actions, actionOptions, pc.lastRuns, scheduleStartTime)
 }
@@ -0,0 +1,368 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+
+	_ "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions"
+	schedcache "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache/fake"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+	_ "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins"
+)
+
+func actionNames(pc *Scheduler) []string {
+	var names []string
+	for _, action := range pc.actions {
+		names = append(names, action.Name())
+	}
+	return names
+}
+
+func TestReloadSchedulerConf(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kube-batch")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv(schedcache.PodNamespaceEnv, "kube-system")
+	os.Setenv(schedcache.PodNameEnv, "kube-batch")
+	defer os.Unsetenv(schedcache.PodNamespaceEnv)
+	defer os.Unsetenv(schedcache.PodNameEnv)
+
+	confPath := filepath.Join(dir, "kube-batch.conf")
+	recorder := record.NewFakeRecorder(10)
+	pc := &Scheduler{
+		cache:         &schedcache.SchedulerCache{Recorder: recorder},
+		schedulerConf: confPath,
+	}
+
+	pc.actions, pc.actionOptions, pc.plugins, err = loadSchedulerConf(defaultSchedulerConf)
+	if err != nil {
+		t.Fatalf("failed to load default configuration: %v", err)
+	}
+	pc.loadedConf = defaultSchedulerConf
+
+	tests := []struct {
+		name    string
+		conf    string
+		actions []string
+		event   bool
+	}{
+		{
+			name:    "new actions",
+			conf:    "actions: \"allocate, preempt\"\ntiers:\n- plugins:\n  - name: gang\n",
+			actions: []string{"allocate", "preempt"},
+		},
+		{
+			name:    "unknown action",
+			conf:    "actions: \"allocate, unknown\"\n",
+			actions: []string{"allocate", "preempt"},
+			event:   true,
+		},
+		{
+			name:    "invalid yaml",
+			conf:    "actions: [",
+			actions: []string{"allocate", "preempt"},
+			event:   true,
+		},
+		{
+			name:    "fixed",
+			conf:    "actions: \"reclaim, allocate\"\n",
+			actions: []string{"reclaim", "allocate"},
+		},
+	}
+
+	for _, test := range tests {
+		if err := ioutil.WriteFile(confPath, []byte(test.conf), 0644); err != nil {
+			t.Fatalf("failed to write configuration: %v", err)
+		}
+
+		pc.reloadSchedulerConf()
+
+		if names := actionNames(pc); !reflect.DeepEqual(names, test.actions) {
+			t.Errorf("case <%s>: expected actions %v, got %v", test.name, test.actions, names)
+		}
+
+		select {
+		case <-recorder.Events:
+			if !test.event {
+				t.Errorf("case <%s>: unexpected event", test.name)
+			}
+		default:
+			if test.event {
+				t.Errorf("case <%s>: expected event of reload failure", test.name)
+			}
+		}
+	}
+}
+
+func TestRunWithInvalidSchedulerConf(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kube-batch")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	confPath := filepath.Join(dir, "kube-batch.conf")
+	if err := ioutil.WriteFile(confPath, []byte("actions: \"allocate\"\nunknown: true\n"), 0644); err != nil {
+		t.Fatalf("failed to write configuration: %v", err)
+	}
+
+	fc := fake.New()
+	pc := &Scheduler{
+		cache:          fc,
+		schedulerConf:  confPath,
+		schedulePeriod: time.Hour,
+	}
+
+	stopCh := make(chan struct{})
+	pc.Run(stopCh)
+	close(stopCh)
+
+	expected, _, _, err := loadSchedulerConf(defaultSchedulerConf)
+	if err != nil {
+		t.Fatalf("failed to load default configuration: %v", err)
+	}
+
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	if names := actionNames(pc); !reflect.DeepEqual(names, actionNames(&Scheduler{actions: expected})) {
+		t.Errorf("expected actions of default configuration, got %v", names)
+	}
+
+	fc.Lock()
+	defer fc.Unlock()
+
+	if len(fc.Events) == 0 || !strings.HasPrefix(fc.Events[0], "Warning "+ConfLoadFailedReason) {
+		t.Errorf("expected event of load failure, got %v", fc.Events)
+	}
+}
+
+func TestLoadActionArguments(t *testing.T) {
+	actions, options, _, err := loadSchedulerConf(`
+actions:
+- name: allocate
+  arguments:
+    allocate.nodeSamplePercentage: "50"
+- name: backfill
+`)
+	if err != nil {
+		t.Fatalf("failed to load configuration: %v", err)
+	}
+
+	if len(actions) != 2 || actions[0].Name() != "allocate" || actions[1].Name() != "backfill" {
+		t.Errorf("expected actions [allocate backfill], got %v", actions)
+	}
+
+	percentage := 100
+	framework.Arguments(options["allocate"].Arguments).GetInt(&percentage, "allocate.nodeSamplePercentage")
+	if percentage != 50 {
+		t.Errorf("expected allocate.nodeSamplePercentage 50, got %d", percentage)
+	}
+
+	if len(options["backfill"].Arguments) != 0 {
+		t.Errorf("expected no argument of backfill, got %v", options["backfill"].Arguments)
+	}
+}
+
+func TestLoadSchedulerConf(t *testing.T) {
+	tests := []struct {
+		name string
+		conf string
+		errs int
+	}{
+		{
+			name: "default configuration",
+			conf: defaultSchedulerConf,
+		},
+		{
+			name: "valid arguments",
+			conf: `
+actions: "allocate, backfill"
+tiers:
+- plugins:
+  - name: nodeorder
+    arguments:
+      nodeaffinity.weight: "2"
+  - name: proportion
+    arguments:
+      proportion.hierarchical: "true"
+`,
+		},
+		{
+			name: "structured actions",
+			conf: `
+actions:
+- name: allocate
+  arguments:
+    allocate.nodeSamplePercentage: "50"
+- name: preempt
+  arguments:
+    preempt.maxVictims: "3"
+- name: backfill
+tiers:
+- plugins:
+  - name: gang
+`,
+		},
+		{
+			name: "invalid action arguments",
+			conf: `
+actions:
+- name: allocate
+  arguments:
+    allocate.nodeSamplePercentage: "half"
+- name: backfill
+  arguments:
+    backfill.unknown: "1"
+- name: allocate
+`,
+			errs: 3,
+		},
+		{
+			name: "invalid interval",
+			conf: `
+actions:
+- name: allocate
+- name: reclaim
+  interval: often
+`,
+			errs: 1,
+		},
+		{
+			name: "unknown action and plugin",
+			conf: `
+actions: "allocate, unknown"
+tiers:
+- plugins:
+  - name: gang
+  - name: unknown
+`,
+			errs: 2,
+		},
+		{
+			name: "invalid arguments",
+			conf: `
+actions: "allocate"
+tiers:
+- plugins:
+  - name: gang
+    arguments:
+      gang.unknown: "1"
+  - name: nodeorder
+    arguments:
+      nodeaffinity.weight: "high"
+  - name: proportion
+    arguments:
+      proportion.hierarchical: "yes"
+`,
+			errs: 3,
+		},
+		{
+			name: "node order weight",
+			conf: `
+actions: "allocate"
+tiers:
+- plugins:
+  - name: nodeorder
+    nodeOrderWeight: 2
+  - name: proportion
+    nodeOrderWeight: -1
+`,
+			errs: 1,
+		},
+		{
+			name: "unknown field",
+			conf: `
+actions: "allocate"
+tiers:
+- plugins:
+  - name: gang
+    disableJobOrdr: true
+`,
+			errs: 1,
+		},
+	}
+
+	for _, test := range tests {
+		_, _, _, err := loadSchedulerConf(test.conf)
+
+		errs := 0
+		if agg, ok := err.(utilerrors.Aggregate); ok {
+			errs = len(agg.Errors())
+		} else if err != nil {
+			errs = 1
+		}
+
+		if errs != test.errs {
+			t.Errorf("case <%s>: expected %d errors, got %d: %v", test.name, test.errs, errs, err)
+		}
+	}
+}
+
+func TestActionDue(t *testing.T) {
+	_, options, _, err := loadSchedulerConf(`
+actions:
+- name: allocate
+- name: preempt
+  interval: 10s
+`)
+	if err != nil {
+		t.Fatalf("failed to load configuration: %v", err)
+	}
+
+	runs := framework.ActionRuns{}
+	start := time.Now()
+
+	tests := []struct {
+		elapsed time.Duration
+		due     map[string]bool
+	}{
+		{elapsed: 0, due: map[string]bool{"allocate": true, "preempt": true}},
+		{elapsed: 1 * time.Second, due: map[string]bool{"allocate": true, "preempt": false}},
+		{elapsed: 9 * time.Second, due: map[string]bool{"allocate": true, "preempt": false}},
+		{elapsed: 10 * time.Second, due: map[string]bool{"allocate": true, "preempt": true}},
+		{elapsed: 11 * time.Second, due: map[string]bool{"allocate": true, "preempt": false}},
+	}
+
+	for i, test := range tests {
+		for name, expected := range test.due {
+			if due := runs.Due(name, options[name].Interval, start.Add(test.elapsed)); due != expected {
+				t.Errorf("case %d: expected action <%s> due %v at %v, got %v",
+					i, name, expected, test.elapsed, due)
+			}
+		}
+	}
+	// The intervals are ignored without runs, e.g. in replay.
+	var none framework.ActionRuns
+	if !none.Due("preempt", options["preempt"].Interval, start) || !none.Due("preempt", options["preempt"].Interval, start) {
+		t.Errorf("expected action <preempt> always due without runs")
+	}
+}
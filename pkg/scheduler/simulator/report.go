@@ -0,0 +1,197 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Report is the result of simulation. Utilization and shares are of CPU.
+type Report struct {
+	// Makespan is when the last task is finished, from the start of simulation.
+	Makespan time.Duration
+	// Utilization is the ratio of the CPU used by tasks to the CPU of cluster
+	// in makespan.
+	Utilization float64
+	// Fairness is Jain's fairness index of the ratios of queues' shares to
+	// their deserved shares; 1 is the fairest.
+	Fairness float64
+	Queues   []*QueueReport
+}
+
+// QueueReport is the result of simulation of a queue.
+type QueueReport struct {
+	Name   string
+	Weight int32
+
+	// Jobs is the number of jobs in queue; Started and Finished are the
+	// number of jobs whose MinMember tasks are started and whose tasks are
+	// all finished.
+	Jobs     int
+	Started  int
+	Finished int
+
+	// Makespan is from the first arrival to the last finish of its jobs.
+	Makespan time.Duration
+	// MeanWait and MaxWait are the time from arrival to start of its jobs.
+	MeanWait time.Duration
+	MaxWait  time.Duration
+	// Utilization is the ratio of the CPU used by its tasks to the CPU of
+	// cluster in makespan of simulation.
+	Utilization float64
+	// Share is the ratio of the CPU used by its tasks to the CPU used by all
+	// tasks; DeservedShare is the ratio of its weight to the total weight of
+	// queues with jobs.
+	Share         float64
+	DeservedShare float64
+	Evictions     int
+}
+
+func (s *Simulator) report() *Report {
+	r := &Report{}
+
+	queues := map[string]*QueueReport{}
+	for _, q := range s.workload.Queues {
+		queues[q.Name] = &QueueReport{Name: q.Name, Weight: q.Weight}
+	}
+
+	var finish time.Time
+	var totalUsage float64
+	firstArrivals := map[string]time.Time{}
+	lastFinishes := map[string]time.Time{}
+	usages := map[string]float64{}
+	waits := map[string]time.Duration{}
+
+	for _, js := range s.jobs {
+		name := js.spec.Queue
+		qr, found := queues[name]
+		if !found {
+			qr = &QueueReport{Name: name}
+			queues[name] = qr
+		}
+
+		qr.Jobs++
+		qr.Evictions += js.evictions
+		usages[name] += js.usage
+		totalUsage += js.usage
+
+		if first, found := firstArrivals[name]; !found || js.arrival.Before(first) {
+			firstArrivals[name] = js.arrival
+		}
+
+		if !js.startTime.IsZero() {
+			qr.Started++
+			wait := js.startTime.Sub(js.arrival)
+			waits[name] += wait
+			if wait > qr.MaxWait {
+				qr.MaxWait = wait
+			}
+		}
+
+		if !js.finishTime.IsZero() {
+			qr.Finished++
+			if js.finishTime.After(lastFinishes[name]) {
+				lastFinishes[name] = js.finishTime
+			}
+			if js.finishTime.After(finish) {
+				finish = js.finishTime
+			}
+		}
+	}
+
+	if !finish.IsZero() {
+		r.Makespan = finish.Sub(s.start)
+	}
+	// The CPU of cluster in makespan, in milli-CPU seconds.
+	capacity := s.capacity.MilliCPU * r.Makespan.Seconds()
+	if capacity > 0 {
+		r.Utilization = totalUsage / capacity
+	}
+
+	var totalWeight int32
+	for _, qr := range queues {
+		if qr.Jobs != 0 {
+			totalWeight += qr.Weight
+		}
+	}
+
+	var sum, sumOfSquares float64
+	for name, qr := range queues {
+		r.Queues = append(r.Queues, qr)
+		if qr.Jobs == 0 {
+			continue
+		}
+
+		if last, found := lastFinishes[name]; found {
+			qr.Makespan = last.Sub(firstArrivals[name])
+		}
+		if qr.Started != 0 {
+			qr.MeanWait = waits[name] / time.Duration(qr.Started)
+		}
+		if capacity > 0 {
+			qr.Utilization = usages[name] / capacity
+		}
+		if totalUsage > 0 {
+			qr.Share = usages[name] / totalUsage
+		}
+		if totalWeight > 0 {
+			qr.DeservedShare = float64(qr.Weight) / float64(totalWeight)
+		}
+
+		if qr.DeservedShare > 0 {
+			x := qr.Share / qr.DeservedShare
+			sum += x
+			sumOfSquares += x * x
+		}
+	}
+	if sumOfSquares > 0 {
+		n := 0
+		for _, qr := range r.Queues {
+			if qr.DeservedShare > 0 {
+				n++
+			}
+		}
+		r.Fairness = sum * sum / (float64(n) * sumOfSquares)
+	}
+
+	sort.Slice(r.Queues, func(i, j int) bool {
+		return r.Queues[i].Name < r.Queues[j].Name
+	})
+
+	return r
+}
+
+// Print prints the report as a table of queues.
+func (r *Report) Print(out io.Writer) {
+	fmt.Fprintf(out, "Makespan: %v\n", r.Makespan)
+	fmt.Fprintf(out, "Utilization: %.1f%%\n", r.Utilization*100)
+	fmt.Fprintf(out, "Fairness: %.3f\n\n", r.Fairness)
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "QUEUE\tWEIGHT\tJOBS\tSTARTED\tFINISHED\tMAKESPAN\tMEAN WAIT\tMAX WAIT\tUTILIZATION\tSHARE\tDESERVED\tEVICTIONS")
+	for _, qr := range r.Queues {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%v\t%v\t%v\t%.1f%%\t%.1f%%\t%.1f%%\t%d\n",
+			qr.Name, qr.Weight, qr.Jobs, qr.Started, qr.Finished,
+			qr.Makespan, qr.MeanWait, qr.MaxWait,
+			qr.Utilization*100, qr.Share*100, qr.DeservedShare*100, qr.Evictions)
+	}
+	w.Flush()
+}
@@ -0,0 +1,449 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"container/heap"
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kbv1 "github.com/kubernetes-sigs/kube-batch/pkg/apis/scheduling/v1alpha1"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/api"
+	schedcache "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/cache"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
+)
+
+const (
+	// defaultSchedulePeriod is the default period between scheduling cycles
+	// in simulated time, the same as kube-batch.
+	defaultSchedulePeriod = time.Second
+	// defaultMaxPods is the number of Pods allowed on node if not given by
+	// inventory, the same as kubelet.
+	defaultMaxPods = 110
+)
+
+// Simulator runs the actions and plugins of kube-batch against a workload
+// trace with a simulated clock: jobs are submitted at their arrival time,
+// the tasks bound by scheduler run for their runtime and then succeed. A
+// scheduling cycle runs every SchedulePeriod while anything is changed, and
+// the clock jumps to the next arrival or completion otherwise.
+type Simulator struct {
+	// SchedulePeriod is the period between scheduling cycles in simulated time.
+	SchedulePeriod time.Duration
+	// EnablePreemption is set to the sessions of simulation.
+	EnablePreemption bool
+
+	workload      *Workload
+	inventory     *Inventory
+	actions       []framework.Action
+	actionOptions map[string]conf.ActionOption
+	tiers         []conf.Tier
+
+	cache  *simCache
+	start  time.Time
+	now    time.Time
+	events eventQueue
+	seq    int
+	// changed is whether any task is bound or evicted in current cycle.
+	changed bool
+
+	jobs     []*jobState
+	tasks    map[api.TaskID]*taskState
+	capacity *api.Resource
+}
+
+// jobState is the simulated status of a job.
+type jobState struct {
+	spec    *JobSpec
+	arrival time.Time
+	tasks   int32
+	// running and completed are the number of running and succeeded tasks.
+	running   int32
+	completed int32
+	// startTime is when MinMember tasks of job are started; finishTime is
+	// when all tasks of job are succeeded.
+	startTime  time.Time
+	finishTime time.Time
+	evictions  int
+	// usage is the milli-CPU seconds used by the tasks of job.
+	usage float64
+}
+
+// taskState is the simulated status of a task.
+type taskState struct {
+	job     *jobState
+	pod     *v1.Pod
+	runtime time.Duration
+	request *api.Resource
+	running bool
+	start   time.Time
+	// generation is increased when task is evicted, so the completion
+	// scheduled before is ignored.
+	generation int
+}
+
+// simCache is the cache of simulation: tasks are bound and evicted at once
+// by updating their Pods in cache, instead of calling API server.
+type simCache struct {
+	*schedcache.SchedulerCache
+	sim *Simulator
+}
+
+func (sc *simCache) Bind(task *api.TaskInfo, hostname string) error {
+	return sc.sim.bind(task, hostname)
+}
+
+func (sc *simCache) Evict(task *api.TaskInfo, reason string) error {
+	return sc.sim.evict(task, reason)
+}
+
+// simClient accepts all status updates of simulation without doing anything.
+type simClient struct{}
+
+func (c *simClient) UpdatePodCondition(pod *v1.Pod, podCondition *v1.PodCondition) (*v1.Pod, error) {
+	return pod, nil
+}
+
+func (c *simClient) UpdatePodGroup(pg *kbv1.PodGroup) (*kbv1.PodGroup, error) {
+	return pg, nil
+}
+
+func (c *simClient) UpdateQueueStatus(queue *kbv1.Queue) (*kbv1.Queue, error) {
+	return queue, nil
+}
+
+func (c *simClient) AllocateVolumes(task *api.TaskInfo, hostname string) error {
+	return nil
+}
+
+func (c *simClient) RevertVolumes(task *api.TaskInfo) {
+}
+
+func (c *simClient) BindVolumes(task *api.TaskInfo) error {
+	return nil
+}
+
+// New returns a simulator of workload on the nodes of inventory, with the
+// actions and tiers of scheduler configuration.
+func New(workload *Workload, inventory *Inventory,
+	actions []framework.Action, actionOptions map[string]conf.ActionOption, tiers []conf.Tier) *Simulator {
+	return &Simulator{
+		SchedulePeriod: defaultSchedulePeriod,
+		workload:       workload,
+		inventory:      inventory,
+		actions:        actions,
+		actionOptions:  actionOptions,
+		tiers:          tiers,
+	}
+}
+
+// Run simulates the workload until all jobs are finished, or no more task
+// can be scheduled, and returns the report of it. The intervals of actions
+// are ignored.
+func (s *Simulator) Run() *Report {
+	s.init()
+
+	for i := range s.workload.Jobs {
+		spec := &s.workload.Jobs[i]
+		s.push(&event{
+			time: s.start.Add(spec.Arrival.Duration),
+			job:  spec,
+		})
+	}
+
+	for {
+		for len(s.events) != 0 && !s.events[0].time.After(s.now) {
+			s.handle(heap.Pop(&s.events).(*event))
+		}
+
+		s.runOnce()
+		if s.changed {
+			s.now = s.now.Add(s.SchedulePeriod)
+			continue
+		}
+		if len(s.events) == 0 {
+			break
+		}
+
+		// Nothing is changed until next event, so skip the cycles before it.
+		cycles := (s.events[0].time.Sub(s.start) + s.SchedulePeriod - 1) / s.SchedulePeriod
+		s.now = s.start.Add(cycles * s.SchedulePeriod)
+	}
+
+	return s.report()
+}
+
+func (s *Simulator) init() {
+	if s.SchedulePeriod <= 0 {
+		s.SchedulePeriod = defaultSchedulePeriod
+	}
+	s.start = time.Unix(0, 0).UTC()
+	s.now = s.start
+	s.tasks = map[api.TaskID]*taskState{}
+	s.capacity = api.EmptyResource()
+
+	sc := schedcache.NewFromDump(&schedcache.ClusterDump{})
+	client := &simClient{}
+	sc.StatusUpdater = client
+	sc.VolumeBinder = client
+	s.cache = &simCache{SchedulerCache: sc, sim: s}
+
+	for _, queue := range s.workload.Queues {
+		sc.AddQueue(&kbv1.Queue{
+			ObjectMeta: metav1.ObjectMeta{Name: queue.Name},
+			Spec:       kbv1.QueueSpec{Weight: queue.Weight},
+		})
+	}
+
+	for _, spec := range s.inventory.Nodes {
+		for i := 0; i < spec.Count || i == 0; i++ {
+			name := spec.Name
+			if spec.Count > 1 {
+				name = fmt.Sprintf("%s-%d", spec.Name, i)
+			}
+
+			allocatable := spec.Allocatable.DeepCopy()
+			if _, found := allocatable[v1.ResourcePods]; !found {
+				allocatable[v1.ResourcePods] = *resource.NewQuantity(defaultMaxPods, resource.DecimalSI)
+			}
+			sc.AddNode(&v1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Labels: spec.Labels},
+				Status: v1.NodeStatus{
+					Capacity:    allocatable,
+					Allocatable: allocatable,
+					Conditions: []v1.NodeCondition{
+						{Type: v1.NodeReady, Status: v1.ConditionTrue},
+					},
+				},
+			})
+			s.capacity.Add(api.NewResource(allocatable))
+		}
+	}
+}
+
+// runOnce runs a scheduling cycle at current simulated time.
+func (s *Simulator) runOnce() {
+	s.changed = false
+
+	ssn := framework.OpenSessionWithConf(s.cache, s.tiers, s.actionOptions, s.EnablePreemption)
+	framework.ExecuteActions(ssn, s.actions, s.actionOptions, nil, s.now)
+	framework.CloseSession(ssn)
+}
+
+// handle handles an event due: submits a job, or completes a task.
+func (s *Simulator) handle(e *event) {
+	if e.job != nil {
+		s.submit(e.job)
+		return
+	}
+
+	ts := e.task
+	if !ts.running || ts.generation != e.generation {
+		return
+	}
+
+	s.stop(ts)
+	ts.job.completed++
+	if ts.job.completed == ts.job.tasks {
+		ts.job.finishTime = s.now
+	}
+
+	// The succeeded Pod is removed at once, so it's not checked by the
+	// plugins of following sessions, e.g. inter-pod affinity.
+	s.cache.DeletePod(ts.pod)
+}
+
+// submit creates the PodGroup and Pods of job.
+func (s *Simulator) submit(spec *JobSpec) {
+	js := &jobState{spec: spec, arrival: s.now}
+	s.jobs = append(s.jobs, js)
+
+	created := metav1.NewTime(s.now)
+	// The Pods are owned by a controller as usual, so the predicate results
+	// of the same tasks are cached.
+	controller := true
+	owner := metav1.OwnerReference{
+		APIVersion: "batch/v1",
+		Kind:       "Job",
+		Name:       spec.Name,
+		UID:        types.UID(fmt.Sprintf("%s-%s", spec.Namespace, spec.Name)),
+		Controller: &controller,
+	}
+	s.cache.AddPodGroup(&kbv1.PodGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         spec.Namespace,
+			Name:              spec.Name,
+			CreationTimestamp: created,
+		},
+		Spec: kbv1.PodGroupSpec{
+			MinMember: spec.MinMember,
+			Queue:     spec.Queue,
+		},
+	})
+
+	for _, task := range spec.Tasks {
+		for i := int32(0); i < task.Replicas; i++ {
+			name := fmt.Sprintf("%s-%s-%d", spec.Name, task.Name, i)
+			pod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					UID:               types.UID(fmt.Sprintf("%s-%s", spec.Namespace, name)),
+					Namespace:         spec.Namespace,
+					Name:              name,
+					CreationTimestamp: created,
+					OwnerReferences:   []metav1.OwnerReference{owner},
+					Annotations: map[string]string{
+						kbv1.GroupNameAnnotationKey: spec.Name,
+						kbv1.TaskRoleKey:            task.Name,
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:      task.Name,
+							Resources: v1.ResourceRequirements{Requests: task.Requests},
+						},
+					},
+					Priority: new(int32),
+				},
+				Status: v1.PodStatus{Phase: v1.PodPending},
+			}
+
+			s.tasks[api.TaskID(pod.UID)] = &taskState{
+				job:     js,
+				pod:     pod,
+				runtime: task.Runtime.Duration,
+				request: api.NewResource(task.Requests),
+			}
+			js.tasks++
+			s.cache.AddPod(pod)
+		}
+	}
+}
+
+// bind starts the task on host, and schedules its completion.
+func (s *Simulator) bind(task *api.TaskInfo, hostname string) error {
+	ts, found := s.tasks[task.UID]
+	if !found || ts.running {
+		return fmt.Errorf("task <%s/%s> is not pending in simulation", task.Namespace, task.Name)
+	}
+
+	pod := ts.pod.DeepCopy()
+	pod.Spec.NodeName = hostname
+	pod.Status.Phase = v1.PodRunning
+	s.updatePod(ts, pod)
+
+	ts.running = true
+	ts.start = s.now
+	js := ts.job
+	js.running++
+	if js.startTime.IsZero() && js.running+js.completed >= js.spec.MinMember {
+		js.startTime = s.now
+	}
+
+	s.push(&event{
+		time:       s.now.Add(ts.runtime),
+		task:       ts,
+		generation: ts.generation,
+	})
+	s.changed = true
+
+	return nil
+}
+
+// evict stops the task, which is pending again as if it's recreated by its
+// controller.
+func (s *Simulator) evict(task *api.TaskInfo, reason string) error {
+	ts, found := s.tasks[task.UID]
+	if !found || !ts.running {
+		return fmt.Errorf("task <%s/%s> is not running in simulation", task.Namespace, task.Name)
+	}
+
+	glog.V(3).Infof("Evict task <%s/%s> at %v: %s", task.Namespace, task.Name, s.now.Sub(s.start), reason)
+
+	s.stop(ts)
+	ts.generation++
+	ts.job.evictions++
+
+	pod := ts.pod.DeepCopy()
+	pod.Spec.NodeName = ""
+	pod.Status.Phase = v1.PodPending
+	s.updatePod(ts, pod)
+	s.changed = true
+
+	return nil
+}
+
+// stop stops the running task, and accounts its usage.
+func (s *Simulator) stop(ts *taskState) {
+	ts.running = false
+	ts.job.running--
+	ts.job.usage += ts.request.MilliCPU * s.now.Sub(ts.start).Seconds()
+}
+
+func (s *Simulator) updatePod(ts *taskState, pod *v1.Pod) {
+	s.cache.UpdatePod(ts.pod, pod)
+	ts.pod = pod
+}
+
+func (s *Simulator) push(e *event) {
+	e.seq = s.seq
+	s.seq++
+	heap.Push(&s.events, e)
+}
+
+// event is the arrival of job, or the completion of task.
+type event struct {
+	time time.Time
+	// seq keeps the order of events at the same time.
+	seq int
+
+	job *JobSpec
+
+	task       *taskState
+	generation int
+}
+
+// eventQueue is the heap of events by time.
+type eventQueue []*event
+
+func (q eventQueue) Len() int { return len(q) }
+
+func (q eventQueue) Less(i, j int) bool {
+	if !q[i].time.Equal(q[j].time) {
+		return q[i].time.Before(q[j].time)
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q eventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *eventQueue) Push(x interface{}) { *q = append(*q, x.(*event)) }
+
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
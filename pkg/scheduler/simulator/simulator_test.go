@@ -0,0 +1,133 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler"
+	_ "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/actions"
+	_ "github.com/kubernetes-sigs/kube-batch/pkg/scheduler/plugins"
+)
+
+const testWorkload = `
+queues:
+- name: q1
+  weight: 1
+- name: q2
+  weight: 1
+jobs:
+- name: j1
+  queue: q1
+  arrival: 0s
+  tasks:
+  - name: worker
+    replicas: 2
+    requests: {cpu: "2", memory: 1Gi}
+    runtime: 10s
+- name: j2
+  queue: q2
+  arrival: 0s
+  tasks:
+  - name: worker
+    replicas: 2
+    requests: {cpu: "2", memory: 1Gi}
+    runtime: 10s
+- name: j3
+  queue: q2
+  arrival: 30s
+  tasks:
+  - name: worker
+    replicas: 1
+    requests: {cpu: "8", memory: 1Gi}
+    runtime: 10s
+`
+
+const testInventory = `
+nodes:
+- name: n
+  count: 2
+  allocatable: {cpu: "2", memory: 4Gi}
+`
+
+func TestSimulator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kube-batch")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	workload, err := LoadWorkload(write("workload.yaml", testWorkload))
+	if err != nil {
+		t.Fatalf("failed to load workload: %v", err)
+	}
+	if workload.Jobs[0].Namespace != "default" || workload.Jobs[0].MinMember != 2 {
+		t.Errorf("expected namespace and MinMember are defaulted, got %v", workload.Jobs[0])
+	}
+	inventory, err := LoadInventory(write("nodes.yaml", testInventory))
+	if err != nil {
+		t.Fatalf("failed to load inventory: %v", err)
+	}
+	if _, err := LoadWorkload(write("invalid.yaml", "jobs:\n- name: j1\n")); err == nil {
+		t.Errorf("expected error of job without queue and tasks")
+	}
+
+	actions, actionOptions, tiers, err := scheduler.LoadSchedulerConf("")
+	if err != nil {
+		t.Fatalf("failed to load default scheduler configuration: %v", err)
+	}
+
+	// j1 and j2 fill the cluster in turn, and j3 never fits.
+	report := New(workload, inventory, actions, actionOptions, tiers).Run()
+
+	if report.Makespan != 20*time.Second {
+		t.Errorf("expected makespan 20s, got %v", report.Makespan)
+	}
+	if report.Utilization != 1 {
+		t.Errorf("expected utilization 1, got %v", report.Utilization)
+	}
+	if report.Fairness != 1 {
+		t.Errorf("expected fairness 1, got %v", report.Fairness)
+	}
+
+	if len(report.Queues) != 2 {
+		t.Fatalf("expected 2 queues, got %d", len(report.Queues))
+	}
+	q1, q2 := report.Queues[0], report.Queues[1]
+	if q1.Jobs != 1 || q1.Finished != 1 || q2.Jobs != 2 || q2.Started != 1 || q2.Finished != 1 {
+		t.Errorf("unexpected jobs of queues: %+v, %+v", q1, q2)
+	}
+	// Either j1 or j2 waits for the other one.
+	if q1.MaxWait+q2.MaxWait != 10*time.Second {
+		t.Errorf("expected one job waits 10s, got %v and %v", q1.MaxWait, q2.MaxWait)
+	}
+	if q1.Share != 0.5 || q2.Share != 0.5 || q1.DeservedShare != 0.5 {
+		t.Errorf("expected equal shares of queues, got %+v, %+v", q1, q2)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Workload is the trace of jobs to simulate, and the queues they're in.
+type Workload struct {
+	Queues []QueueSpec `json:"queues"`
+	Jobs   []JobSpec   `json:"jobs"`
+}
+
+// QueueSpec is a queue of workload.
+type QueueSpec struct {
+	Name   string `json:"name"`
+	Weight int32  `json:"weight"`
+}
+
+// JobSpec is a job of workload, which is simulated as a PodGroup and its Pods.
+type JobSpec struct {
+	Name string `json:"name"`
+	// Namespace is "default" if not set.
+	Namespace string `json:"namespace,omitempty"`
+	Queue     string `json:"queue"`
+	// Arrival is when the job is submitted, from the start of simulation.
+	Arrival metav1.Duration `json:"arrival"`
+	// MinMember is the minimal number of tasks to run the job; all tasks
+	// if not set.
+	MinMember int32      `json:"minMember,omitempty"`
+	Tasks     []TaskSpec `json:"tasks"`
+}
+
+// TaskSpec is a group of identical tasks of job.
+type TaskSpec struct {
+	// Name is the role of tasks, which is the prefix of Pod names.
+	Name     string          `json:"name"`
+	Replicas int32           `json:"replicas"`
+	Requests v1.ResourceList `json:"requests"`
+	// Runtime is how long a task runs once it's bound.
+	Runtime metav1.Duration `json:"runtime"`
+}
+
+// Inventory is the nodes of simulated cluster.
+type Inventory struct {
+	Nodes []NodeSpec `json:"nodes"`
+}
+
+// NodeSpec is a group of identical nodes.
+type NodeSpec struct {
+	// Name is the name of node, or the prefix of node names if Count > 1.
+	Name   string            `json:"name"`
+	Count  int               `json:"count,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	// Allocatable is the resources of each node for tasks.
+	Allocatable v1.ResourceList `json:"allocatable"`
+}
+
+// LoadWorkload reads workload from the JSON or YAML file.
+func LoadWorkload(path string) (*Workload, error) {
+	workload := &Workload{}
+	if err := load(path, workload); err != nil {
+		return nil, err
+	}
+
+	for i := range workload.Jobs {
+		job := &workload.Jobs[i]
+		if len(job.Name) == 0 || len(job.Queue) == 0 || len(job.Tasks) == 0 {
+			return nil, fmt.Errorf("%s: jobs[%d]: name, queue and tasks are required", path, i)
+		}
+		if len(job.Namespace) == 0 {
+			job.Namespace = metav1.NamespaceDefault
+		}
+		if job.MinMember == 0 {
+			for _, task := range job.Tasks {
+				job.MinMember += task.Replicas
+			}
+		}
+	}
+
+	return workload, nil
+}
+
+// LoadInventory reads node inventory from the JSON or YAML file.
+func LoadInventory(path string) (*Inventory, error) {
+	inventory := &Inventory{}
+	if err := load(path, inventory); err != nil {
+		return nil, err
+	}
+
+	for i, node := range inventory.Nodes {
+		if len(node.Name) == 0 || len(node.Allocatable) == 0 {
+			return nil, fmt.Errorf("%s: nodes[%d]: name and allocatable are required", path, i)
+		}
+	}
+
+	return inventory, nil
+}
+
+func load(path string, obj interface{}) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err := yaml.UnmarshalStrict(data, obj); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	return nil
+}
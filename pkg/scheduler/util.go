@@ -19,10 +19,11 @@ package scheduler
 import (
 	"fmt"
 	"io/ioutil"
-	"strings"
 
 	"gopkg.in/yaml.v2"
 
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/conf"
 	"github.com/kubernetes-sigs/kube-batch/pkg/scheduler/framework"
 )
@@ -40,27 +41,124 @@ tiers:
   - name: nodeorder
 `
 
-func loadSchedulerConf(confStr string) ([]framework.Action, []conf.Tier, error) {
+func loadSchedulerConf(confStr string) ([]framework.Action, map[string]conf.ActionOption, []conf.Tier, error) {
 	var actions []framework.Action
+	options := map[string]conf.ActionOption{}
 
 	schedulerConf := &conf.SchedulerConfiguration{}
 
 	buf := make([]byte, len(confStr))
 	copy(buf, confStr)
 
-	if err := yaml.Unmarshal(buf, schedulerConf); err != nil {
-		return nil, nil, err
+	if err := yaml.UnmarshalStrict(buf, schedulerConf); err != nil {
+		return nil, nil, nil, err
+	}
+
+	if errs := validateSchedulerConf(schedulerConf); len(errs) != 0 {
+		return nil, nil, nil, utilerrors.NewAggregate(errs)
+	}
+
+	for _, option := range schedulerConf.Actions {
+		action, _ := framework.GetAction(option.Name)
+		actions = append(actions, action)
+		options[option.Name] = option
+	}
+
+	return actions, options, schedulerConf.Tiers, nil
+}
+
+// validateSchedulerConf checks the names of actions and plugins, and the
+// arguments of actions and plugins against the schema declared by each of them.
+func validateSchedulerConf(schedulerConf *conf.SchedulerConfiguration) []error {
+	var errs []error
+
+	if len(schedulerConf.Actions) == 0 {
+		errs = append(errs, fmt.Errorf("actions: at least one action is required"))
+	}
+
+	actionNames := map[string]bool{}
+	for i, action := range schedulerConf.Actions {
+		path := fmt.Sprintf("actions[%d]", i)
+		if _, found := framework.GetAction(action.Name); !found {
+			errs = append(errs, fmt.Errorf("%s: unknown action %q", path, action.Name))
+			continue
+		}
+
+		if actionNames[action.Name] {
+			errs = append(errs, fmt.Errorf("%s: duplicated action %q", path, action.Name))
+			continue
+		}
+		actionNames[action.Name] = true
+
+		if action.Interval < 0 {
+			errs = append(errs, fmt.Errorf("%s (%s): interval %v must not be negative", path, action.Name, action.Interval))
+		}
+
+		schema, found := framework.GetActionArguments(action.Name)
+		if !found {
+			continue
+		}
+		for _, err := range schema.Validate(action.Arguments) {
+			errs = append(errs, fmt.Errorf("%s (%s): %v", path, action.Name, err))
+		}
+	}
+
+	for i, tier := range schedulerConf.Tiers {
+		for j, plugin := range tier.Plugins {
+			path := fmt.Sprintf("tiers[%d].plugins[%d]", i, j)
+			if len(plugin.Name) == 0 {
+				errs = append(errs, fmt.Errorf("%s: plugin name is required", path))
+				continue
+			}
+
+			if _, found := framework.GetPluginBuilder(plugin.Name); !found {
+				errs = append(errs, fmt.Errorf("%s: unknown plugin %q", path, plugin.Name))
+				continue
+			}
+
+			if plugin.NodeOrderWeight < 0 {
+				errs = append(errs, fmt.Errorf("%s (%s): nodeOrderWeight %d must not be negative",
+					path, plugin.Name, plugin.NodeOrderWeight))
+			}
+
+			schema, found := framework.GetPluginArguments(plugin.Name)
+			if !found {
+				continue
+			}
+			for _, err := range schema.Validate(plugin.Arguments) {
+				errs = append(errs, fmt.Errorf("%s (%s): %v", path, plugin.Name, err))
+			}
+		}
 	}
-	actionNames := strings.Split(schedulerConf.Actions, ",")
-	for _, actionName := range actionNames {
-		if action, found := framework.GetAction(strings.TrimSpace(actionName)); found {
-			actions = append(actions, action)
-		} else {
-			return nil, nil, fmt.Errorf("failed to found Action %s, ignore it", actionName)
+
+	return errs
+}
+
+// CheckSchedulerConf reads and validates the scheduler configuration file;
+// it returns an aggregate of all errors found.
+func CheckSchedulerConf(confPath string) error {
+	confStr, err := readSchedulerConf(confPath)
+	if err != nil {
+		return err
+	}
+
+	_, _, _, err = loadSchedulerConf(confStr)
+	return err
+}
+
+// LoadSchedulerConf reads and loads the scheduler configuration file, or the
+// default configuration if confPath is empty; it's for the tools which run
+// actions without scheduler, e.g. simulator.
+func LoadSchedulerConf(confPath string) ([]framework.Action, map[string]conf.ActionOption, []conf.Tier, error) {
+	confStr := defaultSchedulerConf
+	if len(confPath) != 0 {
+		var err error
+		if confStr, err = readSchedulerConf(confPath); err != nil {
+			return nil, nil, nil, err
 		}
 	}
 
-	return actions, schedulerConf.Tiers, nil
+	return loadSchedulerConf(confStr)
 }
 
 func readSchedulerConf(confPath string) (string, error) {